@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/decentvcs/cli/config"
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/httpvalidation"
+	"github.com/decentvcs/cli/lib/storage"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/decentvcs/cli/models"
+	"github.com/urfave/cli/v2"
+)
+
+// Replace the latest commit of the current branch with one that also contains local changes
+// and/or has a new message.
+//
+// Only allowed if the working copy is on the latest commit and it was made by the current user.
+func Amend(c *cli.Context) error {
+	auth.HasToken()
+
+	if c.Bool("force") {
+		return console.Error("Cannot amend and force push at the same time")
+	}
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	if err = ensureNotDetached(projectConfig); err != nil {
+		return err
+	}
+
+	branch, err := vcs.GetBranchWithCommit(projectConfig, projectConfig.CurrentBranchName)
+	if err != nil {
+		return err
+	}
+
+	headCommit := branch.Commit
+	if headCommit.Index != projectConfig.CurrentCommitIndex {
+		return console.Error("Only the latest commit of branch \"%s\" (#%d) can be amended, but you're on commit #%d", branch.Name, headCommit.Index, projectConfig.CurrentCommitIndex)
+	}
+
+	userID, err := vcs.GetCurrentUserID()
+	if err != nil {
+		return err
+	}
+
+	if headCommit.AuthorID != userID {
+		authorName, err := vcs.GetUserName(headCommit.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		return console.Error("Commit #%d was made by %s; you can only amend your own commits", headCommit.Index, authorName)
+	}
+
+	// Detect local changes
+	fc, err := vcs.DetectFileChanges(headCommit.Files)
+	if err != nil {
+		return err
+	}
+
+	message := c.String("message")
+	if message == "" {
+		message = headCommit.Message
+	} else {
+		commitConfig, err := vcs.GetCommitConfig()
+		if err != nil {
+			return err
+		}
+
+		if err = vcs.ValidateCommitMessage(commitConfig, message); err != nil {
+			return err
+		}
+	}
+
+	changeCount := len(fc.CreatedFilePaths) + len(fc.ModifiedFilePaths) + len(fc.DeletedFilePaths)
+	if changeCount == 0 && message == headCommit.Message {
+		console.Info("Nothing to amend; no changes detected and the message is the same")
+		return nil
+	}
+
+	// The amended commit's changes are relative to the commit before the one being replaced
+	parentCommit, err := vcs.GetParentCommit(projectConfig, headCommit)
+	if err != nil {
+		return err
+	}
+
+	createdFiles := []string{}
+	modifiedFiles := []string{}
+	deletedFiles := []string{}
+	fileDataMap := make(map[string]models.FileData)
+	for path, fileData := range fc.FileDataMap {
+		parentFileData, ok := parentCommit.Files[path]
+		fileData.PatchHashes = parentFileData.PatchHashes
+
+		if !ok {
+			createdFiles = append(createdFiles, path)
+			fileData.Version = 1
+		} else if parentFileData.Hash != fileData.Hash {
+			modifiedFiles = append(modifiedFiles, path)
+			fileData.Version = parentFileData.Version + 1
+		} else {
+			fileData.Version = parentFileData.Version
+		}
+
+		fileDataMap[path] = fileData
+	}
+	for path := range parentCommit.Files {
+		if _, ok := fc.FileDataMap[path]; !ok {
+			deletedFiles = append(deletedFiles, path)
+		}
+	}
+
+	// Prompt user for confirmation
+	if !c.Bool("yes") {
+		console.Warning("Amend commit #%d (\"%s\") on branch \"%s\"? (y/n)", headCommit.Index, headCommit.Message, branch.Name)
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			console.Info("Aborted")
+			return nil
+		}
+	}
+
+	// Upload new file contents.
+	// Modified files are always uploaded as snapshots since patches would be relative to file
+	// versions that are being replaced.
+	uploadHashMap := make(map[string]string)
+	for _, path := range append(append([]string{}, fc.CreatedFilePaths...), fc.ModifiedFilePaths...) {
+		uploadHashMap[path] = fileDataMap[path].Hash
+	}
+
+	if len(uploadHashMap) > 0 {
+		console.Verbose("Uploading files...")
+		err = storage.UploadMany(projectConfig, uploadHashMap)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Replace commit. The server rejects the amend if someone pushed to the branch in the meantime.
+	console.Info("Amending...")
+	bodyJson, _ := json.Marshal(map[string]interface{}{
+		"expected_head_commit_id": headCommit.ID,
+		"message":                 message,
+		"created_files":           createdFiles,
+		"modified_files":          modifiedFiles,
+		"deleted_files":           deletedFiles,
+		"files":                   fileDataMap,
+	})
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/branches/%s/commit", config.I.VCS.ServerHost, projectConfig.ProjectSlug, branch.Name)
+	req, err := http.NewRequest("PUT", reqUrl, bytes.NewBuffer(bodyJson))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	console.Success("Commit #%d amended", headCommit.Index)
+	console.Verbose("Replaced file versions are kept in storage until `dvcs purge` is run")
+	return nil
+}
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"errors"
+	"math/bits"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/decentvcs/cli/models"
+	"github.com/samber/lo"
+	"github.com/urfave/cli/v2"
+)
+
+// Exit code of a `bisect run` command indicating that the commit can't be tested.
+const bisectSkipExitCode = 125
+
+// Result of testing a commit while bisecting.
+type bisectResult int
+
+const (
+	bisectGood bisectResult = iota
+	bisectBad
+	bisectSkip
+)
+
+// Start bisecting between a good and a bad commit.
+func BisectStart(c *cli.Context) error {
+	auth.HasToken()
+
+	if !c.IsSet("good") {
+		return console.Error("Please specify a good commit with --good")
+	}
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	state, err := vcs.GetBisectState()
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		return console.Error("A bisect is already in progress. Run `dvcs bisect reset` to end it first.")
+	}
+
+	currentCommit, err := vcs.GetCommit(projectConfig, projectConfig.CurrentCommitIndex)
+	if err != nil {
+		return err
+	}
+
+	if err = ensureNoLocalChanges(currentCommit.Files); err != nil {
+		return err
+	}
+
+	goodCommit, err := vcs.ResolveRevision(projectConfig, c.String("good"))
+	if err != nil {
+		return err
+	}
+
+	badCommit, err := vcs.ResolveRevision(projectConfig, c.String("bad"))
+	if err != nil {
+		return err
+	}
+
+	if goodCommit.Index >= badCommit.Index {
+		return console.Error("Good commit #%d must be older than bad commit #%d", goodCommit.Index, badCommit.Index)
+	}
+
+	// Test the commits between them in the bad commit's ancestry, including ones made before its
+	// branch was created
+	commits, err := vcs.NewBranchCache(projectConfig).GetCommitsBetween(goodCommit, badCommit)
+	if err != nil {
+		return err
+	}
+
+	candidates := lo.Map(commits, func(commit models.Commit, _ int) int {
+		return commit.Index
+	})
+
+	newState := models.BisectState{
+		OriginalCommitIndex: projectConfig.CurrentCommitIndex,
+		Good:                goodCommit.Index,
+		Bad:                 badCommit.Index,
+		Candidates:          candidates,
+		Skipped:             []int{},
+	}
+
+	_, err = bisectNext(&newState, !c.Bool("yes"))
+	return err
+}
+
+// Mark the current (or specified) commit as good.
+func BisectGood(c *cli.Context) error {
+	return markBisect(c, bisectGood)
+}
+
+// Mark the current (or specified) commit as bad.
+func BisectBad(c *cli.Context) error {
+	return markBisect(c, bisectBad)
+}
+
+// Mark the current (or specified) commit as untestable.
+func BisectSkip(c *cli.Context) error {
+	return markBisect(c, bisectSkip)
+}
+
+// Automatically bisect by running a command on each commit.
+//
+// The command's exit code determines the result: 0 is good, 125 is skipped, 1-127 is bad, and
+// anything else aborts the bisect.
+func BisectRun(c *cli.Context) error {
+	auth.HasToken()
+
+	if c.NArg() == 0 {
+		return console.Error("Please specify the command to run")
+	}
+
+	state, err := getBisectStateOrFail()
+	if err != nil {
+		return err
+	}
+
+	for {
+		projectConfig, err := vcs.GetProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		console.Info("Running \"%s\" on commit #%d...", strings.Join(c.Args().Slice(), " "), projectConfig.CurrentCommitIndex)
+		cmd := exec.Command(c.Args().First(), c.Args().Tail()...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err = cmd.Run()
+
+		result := bisectGood
+		if err != nil {
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				return console.Error("Failed to run command: %v", err)
+			}
+
+			switch code := exitErr.ExitCode(); {
+			case code == bisectSkipExitCode:
+				result = bisectSkip
+			case code > 0 && code < 128:
+				result = bisectBad
+			default:
+				return console.Error("Command exited with code %d, aborting bisect", code)
+			}
+		}
+
+		if err = applyBisectResult(state, projectConfig.CurrentCommitIndex, result); err != nil {
+			return err
+		}
+
+		done, err := bisectNext(state, false)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// End bisecting and sync back to the commit the working copy was on before it started.
+func BisectReset(c *cli.Context) error {
+	auth.HasToken()
+
+	state, err := vcs.GetBisectState()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		console.Info("No bisect in progress")
+		return nil
+	}
+
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	if projectConfig.CurrentCommitIndex != state.OriginalCommitIndex {
+		res, err := vcs.SyncToCommit(projectConfig, state.OriginalCommitIndex, !c.Bool("yes"))
+		if err != nil {
+			return err
+		}
+		if !res.Synced {
+			return console.Error("Failed to sync back to commit #%d", state.OriginalCommitIndex)
+		}
+	}
+
+	return vcs.DeleteBisectState()
+}
+
+// Mark a commit while bisecting and move on to the next one.
+func markBisect(c *cli.Context, result bisectResult) error {
+	auth.HasToken()
+
+	state, err := getBisectStateOrFail()
+	if err != nil {
+		return err
+	}
+
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	commitIndex := projectConfig.CurrentCommitIndex
+	if c.NArg() > 0 {
+		commit, err := vcs.ResolveRevision(projectConfig, c.Args().First())
+		if err != nil {
+			return err
+		}
+		commitIndex = commit.Index
+	}
+
+	if err = applyBisectResult(state, commitIndex, result); err != nil {
+		return err
+	}
+
+	_, err = bisectNext(state, !c.Bool("yes"))
+	return err
+}
+
+// Narrow down the commits left to test based on the result of testing a commit.
+func applyBisectResult(state *models.BisectState, commitIndex int, result bisectResult) error {
+	if commitIndex <= state.Good || commitIndex >= state.Bad {
+		return console.Error("Commit #%d is outside of the range being bisected (#%d to #%d)", commitIndex, state.Good, state.Bad)
+	}
+
+	switch result {
+	case bisectGood:
+		state.Good = commitIndex
+		state.Candidates = lo.Filter(state.Candidates, func(i int, _ int) bool {
+			return i > commitIndex
+		})
+	case bisectBad:
+		state.Bad = commitIndex
+		state.Candidates = lo.Filter(state.Candidates, func(i int, _ int) bool {
+			return i < commitIndex
+		})
+	case bisectSkip:
+		state.Skipped = append(state.Skipped, commitIndex)
+	}
+
+	return nil
+}
+
+// Sync to the next commit to test, or print the first bad commit if there is nothing left to test.
+//
+// Returns true if bisecting is done.
+func bisectNext(state *models.BisectState, confirm bool) (bool, error) {
+	remaining := lo.Filter(state.Candidates, func(i int, _ int) bool {
+		return !lo.Contains(state.Skipped, i)
+	})
+
+	if err := vcs.SaveBisectState(*state); err != nil {
+		return false, err
+	}
+
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return false, err
+	}
+
+	if len(remaining) == 0 {
+		if len(state.Candidates) > 0 {
+			// Only skipped commits are left, so any of them could be the first bad one
+			console.Warning("There are only skipped commits left to test. The first bad commit could be any of:")
+			for _, index := range append(state.Candidates, state.Bad) {
+				console.Warning("  #%d", index)
+			}
+			return true, nil
+		}
+
+		badCommit, err := vcs.GetCommit(projectConfig, state.Bad)
+		if err != nil {
+			return false, err
+		}
+
+		authorName, err := vcs.GetUserName(badCommit.AuthorID)
+		if err != nil {
+			return false, err
+		}
+
+		console.Success("First bad commit is #%d (\"%s\" by %s)", badCommit.Index, badCommit.Message, authorName)
+		console.Info("Run `dvcs bisect reset` to go back to commit #%d", state.OriginalCommitIndex)
+		return true, nil
+	}
+
+	next := remaining[len(remaining)/2]
+	console.Info("Bisecting: %d commits left to test (roughly %d steps)", len(remaining), bits.Len(uint(len(remaining))))
+
+	res, err := vcs.SyncToCommit(projectConfig, next, confirm)
+	if err != nil {
+		return false, err
+	}
+	if !res.Synced && projectConfig.CurrentCommitIndex != next {
+		// The next result would otherwise be recorded for the commit the working copy is still on
+		return false, console.Error("Sync to commit #%d was aborted. Run `dvcs sync %d` before marking it good or bad.", next, next)
+	}
+	if len(res.Conflicts) > 0 {
+		return false, console.Error("Syncing to commit #%d produced conflicts", next)
+	}
+
+	return false, nil
+}
+
+// Get the state of the ongoing bisect, failing if there is none.
+func getBisectStateOrFail() (*models.BisectState, error) {
+	state, err := vcs.GetBisectState()
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, console.Error("No bisect in progress. Run `dvcs bisect start` to start one.")
+	}
+
+	return state, nil
+}
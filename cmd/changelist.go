@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/TwiN/go-color"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/urfave/cli/v2"
+)
+
+// Create a new changelist.
+func NewChangelist(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return console.Error("Changelist name is required")
+	}
+
+	regex := regexp.MustCompile(`^[\w\-\.]+$`)
+	if !regex.MatchString(name) {
+		return console.Error("Invalid changelist name; must be alphanumeric, and can contain dashes or periods")
+	}
+
+	changelist, err := vcs.CreateChangelist(name, c.String("message"))
+	if err != nil {
+		return err
+	}
+
+	console.Success("Created changelist \"%s\"", changelist.Name)
+	return nil
+}
+
+// Add files or directories to a changelist.
+func AddToChangelist(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return console.Error("Please specify a changelist and the paths to add to it")
+	}
+
+	name := c.Args().First()
+	paths, err := getChangelistPathsFromArgs(c.Args().Tail())
+	if err != nil {
+		return err
+	}
+
+	if err = vcs.AddToChangelist(name, paths); err != nil {
+		return err
+	}
+
+	console.Success("Added %d paths to changelist \"%s\"", len(paths), name)
+	return nil
+}
+
+// Remove files or directories from their changelist.
+func RemoveFromChangelist(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return console.Error("Please specify the paths to remove")
+	}
+
+	paths, err := getChangelistPathsFromArgs(c.Args().Slice())
+	if err != nil {
+		return err
+	}
+
+	if err = vcs.RemoveFromChangelists(paths); err != nil {
+		return err
+	}
+
+	console.Success("Removed %d paths from changelists", len(paths))
+	return nil
+}
+
+// List changelists and their paths.
+func ListChangelists(c *cli.Context) error {
+	changelists, err := vcs.GetChangelists()
+	if err != nil {
+		return err
+	}
+
+	if len(changelists) == 0 {
+		console.Info("No changelists")
+		return nil
+	}
+
+	for _, cl := range changelists {
+		if cl.Description != "" {
+			fmt.Printf(color.InBold("%s")+" %s\n", cl.Name, cl.Description)
+		} else {
+			fmt.Println(color.InBold(cl.Name))
+		}
+
+		for _, path := range cl.Paths {
+			fmt.Printf("  %s\n", path)
+		}
+	}
+
+	return nil
+}
+
+// Delete a changelist, leaving its files untouched.
+func DeleteChangelist(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return console.Error("Changelist name is required")
+	}
+
+	if err := vcs.DeleteChangelist(name); err != nil {
+		return err
+	}
+
+	console.Success("Deleted changelist \"%s\"", name)
+	return nil
+}
+
+// Convert path args to relative fs paths from the project root.
+func getChangelistPathsFromArgs(args []string) ([]string, error) {
+	projectConfigPath, err := vcs.GetProjectConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return getPathFilters(filepath.Dir(projectConfigPath), args)
+}
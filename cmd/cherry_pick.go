@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/urfave/cli/v2"
+)
+
+// Apply the changes of a commit from any branch onto the current branch.
+func CherryPick(c *cli.Context) error {
+	auth.HasToken()
+
+	// Parse args
+	if c.NArg() != 1 {
+		return console.Error("Please specify the commit to cherry-pick")
+	}
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	if err = ensureCleanBranchHead(projectConfig); err != nil {
+		return err
+	}
+
+	commit, err := vcs.ResolveRevision(projectConfig, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	branch, err := vcs.GetBranch(projectConfig, commit.BranchID)
+	if err != nil {
+		return err
+	}
+
+	if branch.Name == projectConfig.CurrentBranchName {
+		return console.Error("Commit #%d is already on branch \"%s\"", commit.Index, branch.Name)
+	}
+
+	parentCommit, err := vcs.GetParentCommit(projectConfig, commit)
+	if err != nil {
+		return err
+	}
+
+	// Prompt user for confirmation
+	if !c.Bool("yes") {
+		console.Warning("Apply commit #%d (\"%s\") from branch \"%s\" onto \"%s\"? (y/n)", commit.Index, commit.Message, branch.Name, projectConfig.CurrentBranchName)
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			console.Info("Aborted")
+			return nil
+		}
+	}
+
+	// Apply the commit's changes relative to its parent
+	paths := append(append(append([]string{}, commit.CreatedFiles...), commit.ModifiedFiles...), commit.DeletedFiles...)
+	res, err := vcs.ApplyFileChanges(projectConfig, "cherry-pick", fmt.Sprintf("#%d", commit.Index), parentCommit.Files, commit.Files, paths)
+	if err != nil {
+		return err
+	}
+
+	if len(res.Conflicts) > 0 {
+		printConflicts(res.Conflicts)
+		console.Warning("Cherry-picked with %d conflicts. Resolve them, then push.", len(res.Conflicts))
+		return nil
+	}
+
+	if len(res.Applied) == 0 {
+		console.Info("Changes of commit #%d are already on branch \"%s\"", commit.Index, projectConfig.CurrentBranchName)
+		return nil
+	}
+
+	message := fmt.Sprintf("Cherry-pick #%d from %s: %s", commit.Index, branch.Name, commit.Message)
+	return Push(c, WithNoConfirm(), WithMessage(message))
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/TwiN/go-color"
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/decentvcs/cli/models"
+	"github.com/urfave/cli/v2"
+)
+
+// List the commits and files unique to each of two branches since they diverged.
+func CompareBranches(c *cli.Context) error {
+	auth.HasToken()
+
+	// Parse args
+	if c.NArg() == 0 || c.NArg() > 2 {
+		return console.Error("Please specify one or two branches to compare")
+	}
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	// Compare against the current branch if only one branch is specified
+	oursName := projectConfig.CurrentBranchName
+	theirsName := c.Args().Get(0)
+	if c.NArg() == 2 {
+		oursName = c.Args().Get(0)
+		theirsName = c.Args().Get(1)
+	}
+
+	divergence, err := vcs.GetBranchDivergence(projectConfig, oursName, theirsName)
+	if err != nil {
+		return err
+	}
+
+	console.Info("Branches diverged at commit #%d (\"%s\")", divergence.Base.Index, divergence.Base.Message)
+
+	// Commits
+	if err = printBranchCommits(oursName, divergence.Ours); err != nil {
+		return err
+	}
+	if err = printBranchCommits(theirsName, divergence.Theirs); err != nil {
+		return err
+	}
+
+	// Files changed on each side since the branches diverged
+	oursDiffs := getBranchFileDiffs(divergence.Base, divergence.Ours)
+	theirsDiffs := getBranchFileDiffs(divergence.Base, divergence.Theirs)
+
+	oursOnly := []fileDiff{}
+	both := []string{}
+	for path, d := range oursDiffs {
+		if _, ok := theirsDiffs[path]; ok {
+			both = append(both, path)
+		} else {
+			oursOnly = append(oursOnly, d)
+		}
+	}
+
+	theirsOnly := []fileDiff{}
+	for path, d := range theirsDiffs {
+		if _, ok := oursDiffs[path]; !ok {
+			theirsOnly = append(theirsOnly, d)
+		}
+	}
+
+	printBranchFileDiffs(oursName, oursOnly)
+	printBranchFileDiffs(theirsName, theirsOnly)
+
+	if len(both) > 0 {
+		sort.Strings(both)
+		fmt.Println(color.InBold(color.InYellow(fmt.Sprintf("Files changed on both branches (%d):", len(both)))))
+		for _, path := range both {
+			fmt.Printf("  %s\t%s\t%s\n", oursDiffs[path].Status, theirsDiffs[path].Status, path)
+		}
+	}
+
+	return nil
+}
+
+// Print commits made only on a branch.
+func printBranchCommits(branchName string, commits []models.Commit) error {
+	fmt.Println(color.InBold(fmt.Sprintf("Commits only on %s (%d):", branchName, len(commits))))
+	for _, commit := range commits {
+		authorName, err := vcs.GetUserName(commit.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("  "+color.InCyan("#%d")+" %s "+color.InGray("(%s, %s)")+"\n", commit.Index, commit.Message, authorName, commit.CreatedAt.Local().Format(constants.TimeFormat))
+	}
+
+	return nil
+}
+
+// Print files changed only on a branch.
+func printBranchFileDiffs(branchName string, diffs []fileDiff) {
+	if len(diffs) == 0 {
+		return
+	}
+
+	diffs = filterFileDiffs(diffs, nil)
+	fmt.Println(color.InBold(fmt.Sprintf("Files changed only on %s (%d):", branchName, len(diffs))))
+	for _, d := range diffs {
+		fmt.Printf("  %s\t%s\n", d.Status, d.Path)
+	}
+}
+
+// Get the files changed by a branch's commits since the base commit, keyed by path.
+func getBranchFileDiffs(base models.Commit, commits []models.Commit) map[string]fileDiff {
+	res := make(map[string]fileDiff)
+	if len(commits) == 0 {
+		return res
+	}
+
+	for _, d := range getCommitFileDiffs(base.Files, commits[len(commits)-1].Files) {
+		res[d.Path] = d
+	}
+
+	return res
+}
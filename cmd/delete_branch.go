@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/decentvcs/cli/config"
 	"github.com/decentvcs/cli/constants"
@@ -57,15 +58,42 @@ func DeleteBranch(c *cli.Context) error {
 	}
 
 	// Prevent deletion of current branch
-	if branch.ID == projectConfig.CurrentBranchName {
+	if branch.Name == projectConfig.CurrentBranchName {
 		return console.Error("You cannot delete the current branch. Please switch to another branch first.")
 	}
 
+	// Prevent deletion of default branch
+	project, err := vcs.GetProject(projectConfig)
+	if err != nil {
+		return err
+	}
+
+	if branch.ID == project.DefaultBranchID {
+		return console.Error("You cannot delete the default branch. Please set another branch as the default first.")
+	}
+
+	// Warn about commits that would only be reachable through the deleted branch
+	unmergedCommits, err := vcs.GetUnmergedBranchCommits(projectConfig, branch)
+	if err != nil {
+		return err
+	}
+
+	if len(unmergedCommits) > 0 {
+		console.Warning("Branch \"%s\" has %d commits whose changes are not in any other branch:", branch.Name, len(unmergedCommits))
+		for _, commit := range unmergedCommits {
+			console.Warning("  #%d: %s", commit.Index, commit.Message)
+		}
+	}
+
 	// Ask for confirmation
 	if !c.Bool("yes") {
-		console.Warning("Are you sure you want to delete the branch \"%s\"?", branchName)
+		console.Warning("Are you sure you want to delete the branch \"%s\"? (y/n)", branch.Name)
 		var answer string
 		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			console.Info("Aborted")
+			return nil
+		}
 	}
 
 	// Soft-delete branch
@@ -83,6 +111,31 @@ func DeleteBranch(c *cli.Context) error {
 		return err
 	}
 	res.Body.Close()
-	console.Success("Branch deleted")
+	console.Success("Branch deleted. You can undo this with `dvcs branch restore %s`.", branch.Name)
+	return nil
+}
+
+// Restore a deleted branch.
+func RestoreBranch(c *cli.Context) error {
+	auth.HasToken()
+
+	// Get the branch name
+	branchName := c.Args().First()
+	if branchName == "" {
+		return console.Error("You must specify a branch name")
+	}
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	branch, err := vcs.RestoreBranch(projectConfig, branchName)
+	if err != nil {
+		return err
+	}
+
+	console.Success("Branch \"%s\" restored", branch.Name)
 	return nil
 }
@@ -0,0 +1,541 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TwiN/go-color"
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/storage"
+	"github.com/decentvcs/cli/lib/system"
+	"github.com/decentvcs/cli/lib/util"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/decentvcs/cli/models"
+	"github.com/urfave/cli/v2"
+)
+
+// Amount of unchanged lines printed around each change in a diff.
+const diffContextLines = 3
+
+// Max width of the +/- bar printed by `--stat`.
+const diffStatBarWidth = 40
+
+// A changed file in a diff.
+type fileDiff struct {
+	// Relative fs path
+	Path string
+	// "A" (added), "M" (modified) or "D" (deleted)
+	Status string
+	// Local path to the old contents. Empty if the file was added.
+	OldPath string
+	// Local path to the new contents. Empty if the file was deleted.
+	NewPath string
+	OldHash string
+	NewHash string
+	// Sizes in bytes. 0 if unknown or the side doesn't exist.
+	OldSize int64
+	NewSize int64
+}
+
+// Print diff of local changes, or between two commits
+func Diff(c *cli.Context) error {
+	auth.HasToken()
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	projectConfigPath, err := vcs.GetProjectConfigPath()
+	if err != nil {
+		return err
+	}
+
+	projectPath := filepath.Dir(projectConfigPath)
+
+	// Leading args are revisions if both resolve to commits, otherwise all args are paths
+	args := c.Args().Slice()
+	if len(args) >= 2 {
+		fromCommit, fromErr := vcs.ResolveRevision(projectConfig, args[0])
+		toCommit, toErr := vcs.ResolveRevision(projectConfig, args[1])
+		if fromErr == nil && toErr == nil {
+			return diffCommits(c, projectConfig, projectPath, fromCommit, toCommit, args[2:])
+		}
+
+		console.Verbose("Treating \"%s\" and \"%s\" as paths since they aren't both revisions", args[0], args[1])
+	}
+
+	// Get current commit
+	currentCommit, err := vcs.GetCommit(projectConfig, projectConfig.CurrentCommitIndex)
+	if err != nil {
+		return err
+	}
+
+	// Detect local changes
+	fc, err := vcs.DetectFileChanges(currentCommit.Files, vcs.WithQuiet())
+	if err != nil {
+		return err
+	}
+
+	filters, err := getPathFilters(projectPath, args)
+	if err != nil {
+		return err
+	}
+
+	diffs := []fileDiff{}
+	for _, path := range fc.CreatedFilePaths {
+		diffs = append(diffs, fileDiff{
+			Path:    path,
+			Status:  "A",
+			NewPath: filepath.Join(projectPath, path),
+			NewHash: fc.FileDataMap[path].Hash,
+			NewSize: fc.FileDataMap[path].Size,
+		})
+	}
+	for _, path := range fc.ModifiedFilePaths {
+		diffs = append(diffs, fileDiff{
+			Path:    path,
+			Status:  "M",
+			NewPath: filepath.Join(projectPath, path),
+			OldHash: currentCommit.Files[path].Hash,
+			NewHash: fc.FileDataMap[path].Hash,
+			OldSize: currentCommit.Files[path].Size,
+			NewSize: fc.FileDataMap[path].Size,
+		})
+	}
+	for _, path := range fc.DeletedFilePaths {
+		diffs = append(diffs, fileDiff{
+			Path:    path,
+			Status:  "D",
+			OldHash: currentCommit.Files[path].Hash,
+			OldSize: currentCommit.Files[path].Size,
+		})
+	}
+
+	diffs = filterFileDiffs(diffs, filters)
+	if len(diffs) == 0 {
+		console.Info("No changes detected")
+		return nil
+	}
+
+	if c.Bool("name-status") {
+		printDiffNameStatus(diffs)
+		return nil
+	}
+
+	// Download committed versions of modified and deleted files
+	tmpDirPath, err := os.MkdirTemp(system.GetTempDir(), "diff-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDirPath)
+
+	err = downloadFileDiffs(projectConfig, tmpDirPath, diffs)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("stat") {
+		return printDiffStat(diffs)
+	}
+
+	return printFileDiffs(diffs)
+}
+
+// Print diff between the files of two commits.
+// Only paths and sizes are printed unless file contents are requested.
+func diffCommits(c *cli.Context, projectConfig models.ProjectConfig, projectPath string, fromCommit models.Commit, toCommit models.Commit, pathArgs []string) error {
+	filters, err := getPathFilters(projectPath, pathArgs)
+	if err != nil {
+		return err
+	}
+
+	diffs := filterFileDiffs(getCommitFileDiffs(fromCommit.Files, toCommit.Files), filters)
+	if len(diffs) == 0 {
+		console.Info("No differences between commits #%d and #%d", fromCommit.Index, toCommit.Index)
+		return nil
+	}
+
+	if c.Bool("name-status") {
+		printDiffNameStatus(diffs)
+		return nil
+	}
+
+	if !c.Bool("patch") && !c.Bool("stat") {
+		printDiffSizes(diffs)
+		return nil
+	}
+
+	// Download both versions of each file
+	tmpDirPath, err := os.MkdirTemp(system.GetTempDir(), "diff-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDirPath)
+
+	err = downloadFileDiffs(projectConfig, tmpDirPath, diffs)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("stat") {
+		return printDiffStat(diffs)
+	}
+
+	return printFileDiffs(diffs)
+}
+
+// Compare the file maps of two commits.
+func getCommitFileDiffs(from map[string]models.FileData, to map[string]models.FileData) []fileDiff {
+	diffs := []fileDiff{}
+	for path, toData := range to {
+		fromData, ok := from[path]
+		if !ok {
+			diffs = append(diffs, fileDiff{
+				Path:    path,
+				Status:  "A",
+				NewHash: toData.Hash,
+				NewSize: toData.Size,
+			})
+		} else if fromData.Hash != toData.Hash {
+			diffs = append(diffs, fileDiff{
+				Path:    path,
+				Status:  "M",
+				OldHash: fromData.Hash,
+				NewHash: toData.Hash,
+				OldSize: fromData.Size,
+				NewSize: toData.Size,
+			})
+		}
+	}
+
+	for path, fromData := range from {
+		if _, ok := to[path]; !ok {
+			diffs = append(diffs, fileDiff{
+				Path:    path,
+				Status:  "D",
+				OldHash: fromData.Hash,
+				OldSize: fromData.Size,
+			})
+		}
+	}
+
+	return diffs
+}
+
+// Convert path args to fs paths relative to the project root.
+func getPathFilters(projectPath string, args []string) ([]string, error) {
+	filters := []string{}
+	for _, arg := range args {
+		absPath, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		relPath, err := filepath.Rel(projectPath, absPath)
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			return nil, console.Error("Path \"%s\" is outside of the project", arg)
+		}
+
+		filters = append(filters, relPath)
+	}
+
+	return filters, nil
+}
+
+// Returns the diffs matching any of the path filters (either the path itself or a parent
+// directory), sorted by path. Returns all diffs if there are no filters.
+func filterFileDiffs(diffs []fileDiff, filters []string) []fileDiff {
+	res := []fileDiff{}
+	for _, d := range diffs {
+		if vcs.PathMatches(d.Path, filters) {
+			res = append(res, d)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+
+	return res
+}
+
+// Download the contents of all diff sides that have a hash but no local path into `dest`, setting
+// their paths.
+func downloadFileDiffs(projectConfig models.ProjectConfig, dest string, diffs []fileDiff) error {
+	oldHashMap := make(map[string]string)
+	newHashMap := make(map[string]string)
+	for _, d := range diffs {
+		if d.OldPath == "" && d.OldHash != "" {
+			oldHashMap[d.Path] = d.OldHash
+		}
+		if d.NewPath == "" && d.NewHash != "" {
+			newHashMap[d.Path] = d.NewHash
+		}
+	}
+
+	oldDirPath := filepath.Join(dest, "old")
+	newDirPath := filepath.Join(dest, "new")
+	for dirPath, hashMap := range map[string]map[string]string{oldDirPath: oldHashMap, newDirPath: newHashMap} {
+		if len(hashMap) == 0 {
+			continue
+		}
+
+		err := storage.DownloadManyCached(projectConfig, dirPath, hashMap)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, d := range diffs {
+		if _, ok := oldHashMap[d.Path]; ok {
+			diffs[i].OldPath = filepath.Join(oldDirPath, d.Path)
+		}
+		if _, ok := newHashMap[d.Path]; ok {
+			diffs[i].NewPath = filepath.Join(newDirPath, d.Path)
+		}
+	}
+
+	return nil
+}
+
+// Print status letter and path of each diff.
+func printDiffNameStatus(diffs []fileDiff) {
+	for _, d := range diffs {
+		fmt.Printf("%s\t%s\n", d.Status, d.Path)
+	}
+}
+
+// Print status, path and size of each diff, followed by totals.
+func printDiffSizes(diffs []fileDiff) {
+	added, modified, deleted := 0, 0, 0
+	var sizeDelta int64
+	for _, d := range diffs {
+		sizeDelta += d.NewSize - d.OldSize
+		switch d.Status {
+		case "A":
+			added++
+			fmt.Printf(color.InGreen("  + %s (%s)\n"), d.Path, util.FormatBytesSize(d.NewSize))
+		case "M":
+			modified++
+			fmt.Printf(color.InBlue("  * %s (%s -> %s)\n"), d.Path, util.FormatBytesSize(d.OldSize), util.FormatBytesSize(d.NewSize))
+		case "D":
+			deleted++
+			fmt.Printf(color.InRed("  - %s (%s)\n"), d.Path, util.FormatBytesSize(d.OldSize))
+		}
+	}
+
+	fmt.Printf("%d files changed (%d added, %d modified, %d deleted), %s\n", len(diffs), added, modified, deleted, formatSizeDelta(sizeDelta))
+}
+
+// Read the lines of both sides of a diff.
+// Returns false if either side isn't a text file that can be diffed.
+func readFileDiffLines(d fileDiff) (oldLines []string, newLines []string, isText bool, err error) {
+	oldLines = []string{}
+	newLines = []string{}
+
+	for _, side := range []struct {
+		path  string
+		lines *[]string
+	}{{d.OldPath, &oldLines}, {d.NewPath, &newLines}} {
+		if side.path == "" {
+			continue
+		}
+
+		mergeable, err := vcs.IsMergeable(side.path)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if !mergeable {
+			return nil, nil, false, nil
+		}
+
+		content, err := os.ReadFile(side.path)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		*side.lines = vcs.SplitLines(string(content))
+	}
+
+	return oldLines, newLines, true, nil
+}
+
+// Print unified diffs of text files and summaries of binary files.
+func printFileDiffs(diffs []fileDiff) error {
+	for _, d := range diffs {
+		oldLines, newLines, isText, err := readFileDiffLines(d)
+		if err != nil {
+			return err
+		}
+
+		if !isText {
+			if err = printBinaryFileDiff(d); err != nil {
+				return err
+			}
+			continue
+		}
+
+		oldName, newName := "a/"+d.Path, "b/"+d.Path
+		if d.OldPath == "" {
+			oldName = "/dev/null"
+		}
+		if d.NewPath == "" {
+			newName = "/dev/null"
+		}
+
+		fmt.Println(color.InBold(fmt.Sprintf("--- %s", oldName)))
+		fmt.Println(color.InBold(fmt.Sprintf("+++ %s", newName)))
+
+		for _, line := range vcs.FormatUnifiedDiff(vcs.DiffLines(oldLines, newLines), diffContextLines) {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				fmt.Println(color.InCyan(line))
+			case strings.HasPrefix(line, "+"):
+				fmt.Println(color.InGreen(line))
+			case strings.HasPrefix(line, "-"):
+				fmt.Println(color.InRed(line))
+			default:
+				fmt.Println(line)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Print size delta, hashes and detected type of a binary (or too large) file.
+func printBinaryFileDiff(d fileDiff) error {
+	var oldSize, newSize int64
+	typePath := d.NewPath
+	if d.OldPath != "" {
+		fileInfo, err := os.Stat(d.OldPath)
+		if err != nil {
+			return err
+		}
+		oldSize = fileInfo.Size()
+	}
+	if d.NewPath != "" {
+		fileInfo, err := os.Stat(d.NewPath)
+		if err != nil {
+			return err
+		}
+		newSize = fileInfo.Size()
+	} else {
+		typePath = d.OldPath
+	}
+
+	fileType, err := vcs.DetectFileType(typePath)
+	if err != nil {
+		return err
+	}
+
+	oldHash, newHash := d.OldHash, d.NewHash
+	if oldHash == "" {
+		oldHash = "none"
+	}
+	if newHash == "" {
+		newHash = "none"
+	}
+
+	fmt.Println(color.InBold(fmt.Sprintf("Binary file %s %s", d.Path, diffStatusVerb(d.Status))))
+	fmt.Printf("  Size: %s -> %s (%s)\n", util.FormatBytesSize(oldSize), util.FormatBytesSize(newSize), formatSizeDelta(newSize-oldSize))
+	fmt.Printf("  Hash: %s -> %s\n", oldHash, newHash)
+	fmt.Printf("  Type: %s\n", fileType)
+	return nil
+}
+
+// Print amount of changed lines per file and in total.
+func printDiffStat(diffs []fileDiff) error {
+	type stat struct {
+		path       string
+		insertions int
+		deletions  int
+		isText     bool
+		sizeDelta  int64
+	}
+
+	stats := []stat{}
+	maxPathLen := 0
+	maxChanges := 0
+	totalInsertions, totalDeletions := 0, 0
+	for _, d := range diffs {
+		oldLines, newLines, isText, err := readFileDiffLines(d)
+		if err != nil {
+			return err
+		}
+
+		s := stat{path: d.Path, isText: isText}
+		if isText {
+			s.insertions, s.deletions = vcs.CountDiffLines(vcs.DiffLines(oldLines, newLines))
+			totalInsertions += s.insertions
+			totalDeletions += s.deletions
+			if s.insertions+s.deletions > maxChanges {
+				maxChanges = s.insertions + s.deletions
+			}
+		} else {
+			for _, side := range []struct {
+				path string
+				sign int64
+			}{{d.OldPath, -1}, {d.NewPath, 1}} {
+				if side.path == "" {
+					continue
+				}
+				fileInfo, err := os.Stat(side.path)
+				if err != nil {
+					return err
+				}
+				s.sizeDelta += side.sign * fileInfo.Size()
+			}
+		}
+
+		if len(d.Path) > maxPathLen {
+			maxPathLen = len(d.Path)
+		}
+		stats = append(stats, s)
+	}
+
+	for _, s := range stats {
+		if !s.isText {
+			fmt.Printf(" %-*s | Bin %s\n", maxPathLen, s.path, formatSizeDelta(s.sizeDelta))
+			continue
+		}
+
+		// Scale bar down if needed
+		plus, minus := s.insertions, s.deletions
+		if maxChanges > diffStatBarWidth {
+			plus = (plus*diffStatBarWidth + maxChanges - 1) / maxChanges
+			minus = (minus*diffStatBarWidth + maxChanges - 1) / maxChanges
+		}
+
+		fmt.Printf(" %-*s | %d %s%s\n", maxPathLen, s.path, s.insertions+s.deletions, color.InGreen(strings.Repeat("+", plus)), color.InRed(strings.Repeat("-", minus)))
+	}
+
+	fmt.Printf(" %d files changed, %d insertions(+), %d deletions(-)\n", len(stats), totalInsertions, totalDeletions)
+	return nil
+}
+
+// Returns a verb describing a diff status, e.g. "added" for "A".
+func diffStatusVerb(status string) string {
+	switch status {
+	case "A":
+		return "added"
+	case "D":
+		return "deleted"
+	default:
+		return "modified"
+	}
+}
+
+// Format a signed size difference, e.g. "+1.50 KB".
+func formatSizeDelta(delta int64) string {
+	if delta < 0 {
+		return "-" + util.FormatBytesSize(-delta)
+	}
+
+	return "+" + util.FormatBytesSize(delta)
+}
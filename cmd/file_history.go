@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/TwiN/go-color"
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/util"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/decentvcs/cli/models"
+	"github.com/samber/lo"
+	"github.com/urfave/cli/v2"
+)
+
+// Print every commit that created, modified, or deleted a file
+func PrintFileHistory(c *cli.Context) error {
+	auth.HasToken()
+
+	// Parse args
+	if c.NArg() != 1 {
+		return console.Error("Please specify a file path")
+	}
+
+	limit := c.Int("limit")
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	projectConfigPath, err := vcs.GetProjectConfigPath()
+	if err != nil {
+		return err
+	}
+
+	filters, err := getPathFilters(filepath.Dir(projectConfigPath), c.Args().Slice())
+	if err != nil {
+		return err
+	}
+	path := filters[0]
+
+	// Print commits that touched the file
+	count := 0
+	err = forEachCommit(projectConfig, 0, historyFetchPageSize, func(commit models.CommitWithBranch) (bool, error) {
+		var action string
+		switch {
+		case lo.Contains(commit.CreatedFiles, path):
+			action = color.InGreen("created")
+		case lo.Contains(commit.ModifiedFiles, path):
+			action = color.InBlue("modified")
+		case lo.Contains(commit.DeletedFiles, path):
+			action = color.InRed("deleted")
+		default:
+			return true, nil
+		}
+
+		authorName, err := vcs.GetUserName(commit.AuthorID)
+		if err != nil {
+			return false, err
+		}
+
+		details := ""
+		if fileData, ok := commit.Files[path]; ok {
+			details = fmt.Sprintf(" v%d, %s", fileData.Version, util.FormatBytesSize(fileData.Size))
+		}
+
+		createdAt := commit.CreatedAt.Format(time.RFC1123)
+		fmt.Printf("%s "+color.InCyan(color.InBold("[%s; #%d]"))+" %s%s "+color.InGray("(%s)")+" %s\n", createdAt, commit.Branch.Name, commit.Index, action, details, authorName, commit.Message)
+
+		count++
+		return limit <= 0 || count < limit, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		console.Info("No commits found for \"%s\"", path)
+	}
+
+	return nil
+}
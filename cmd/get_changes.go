@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 
+	"github.com/TwiN/go-color"
 	"github.com/decentvcs/cli/config"
 	"github.com/decentvcs/cli/constants"
 	"github.com/decentvcs/cli/lib/auth"
@@ -62,5 +64,42 @@ func GetChanges(c *cli.Context) error {
 		return nil
 	}
 
+	// Print changed files grouped by changelist
+	changelists, err := vcs.GetChangelists()
+	if err != nil {
+		return err
+	}
+
+	if len(changelists) == 0 {
+		return nil
+	}
+
+	changedPaths := append(append(append([]string{}, fc.CreatedFilePaths...), fc.ModifiedFilePaths...), fc.DeletedFilePaths...)
+	sort.Strings(changedPaths)
+
+	groups := make(map[string][]string)
+	for _, path := range changedPaths {
+		name := vcs.GetFileChangelistName(changelists, path)
+		groups[name] = append(groups[name], path)
+	}
+
+	for _, cl := range changelists {
+		if len(groups[cl.Name]) == 0 {
+			continue
+		}
+
+		fmt.Println(color.InBold(fmt.Sprintf("Changelist \"%s\":", cl.Name)))
+		for _, path := range groups[cl.Name] {
+			fmt.Printf("  %s\n", path)
+		}
+	}
+
+	if len(groups[""]) > 0 {
+		fmt.Println(color.InBold("Not in a changelist:"))
+		for _, path := range groups[""] {
+			fmt.Printf("  %s\n", path)
+		}
+	}
+
 	return nil
 }
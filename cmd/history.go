@@ -3,19 +3,49 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/TwiN/go-color"
-	"github.com/decentvcs/cli/config"
-	"github.com/decentvcs/cli/constants"
 	"github.com/decentvcs/cli/lib/auth"
-	"github.com/decentvcs/cli/lib/httpvalidation"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/util"
 	"github.com/decentvcs/cli/lib/vcs"
 	"github.com/decentvcs/cli/models"
 	"github.com/urfave/cli/v2"
 )
 
+// Amount of commits requested from the server at a time while filtering history.
+const historyFetchPageSize = 100
+
+// Commit as printed by `dvcs history`, also used as the data for `--format` templates.
+type historyEntry struct {
+	Index   int       `json:"index"`
+	Branch  string    `json:"branch"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
+	// Whether the working copy is on this commit
+	Current       bool     `json:"current"`
+	CreatedFiles  []string `json:"created_files,omitempty"`
+	ModifiedFiles []string `json:"modified_files,omitempty"`
+	DeletedFiles  []string `json:"deleted_files,omitempty"`
+}
+
+// Filters for `dvcs history`.
+type historyFilter struct {
+	Branch  string
+	Author  string
+	Since   time.Time
+	Until   time.Time
+	Message string
+	Paths   []string
+}
+
 // Print commit history
 func PrintHistory(c *cli.Context) error {
 	auth.HasToken()
@@ -26,41 +56,227 @@ func PrintHistory(c *cli.Context) error {
 		limit = 10
 	}
 
+	page := c.Int("page")
+	if page <= 0 {
+		page = 1
+	}
+
 	// Get project config
 	projectConfig, err := vcs.GetProjectConfig()
 	if err != nil {
 		return err
 	}
 
-	// Get commits up to limit
-	httpClient := http.Client{}
-	reqUrl := fmt.Sprintf("%s/projects/%s/commits?limit=%d", config.I.VCS.ServerHost, projectConfig.ProjectSlug, limit)
-	req, err := http.NewRequest("GET", reqUrl, nil)
+	projectConfigPath, err := vcs.GetProjectConfigPath()
 	if err != nil {
 		return err
 	}
-	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
-	res, err := httpClient.Do(req)
-	if err != nil {
-		return err
+
+	filter := historyFilter{
+		Branch:  c.String("branch"),
+		Author:  strings.ToLower(c.String("author")),
+		Message: strings.ToLower(c.String("grep")),
 	}
-	if err = httpvalidation.ValidateResponse(res); err != nil {
-		return err
+
+	if c.IsSet("since") {
+		filter.Since, err = util.ParseDate(c.String("since"), false)
+		if err != nil {
+			return err
+		}
 	}
-	defer res.Body.Close()
 
-	// Parse response
-	var commits []models.CommitWithBranch
-	err = json.NewDecoder(res.Body).Decode(&commits)
+	if c.IsSet("until") {
+		filter.Until, err = util.ParseDate(c.String("until"), true)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.IsSet("path") {
+		filter.Paths, err = getPathFilters(filepath.Dir(projectConfigPath), c.StringSlice("path"))
+		if err != nil {
+			return err
+		}
+	}
+
+	var tmpl *template.Template
+	if c.IsSet("format") {
+		tmpl, err = template.New("format").Parse(c.String("format"))
+		if err != nil {
+			return console.Error("Invalid format: %v", err)
+		}
+	}
+
+	// Get matching commits, skipping previous pages. Without filters, the server skips them and only
+	// the commits to print are requested.
+	skip := (page - 1) * limit
+	offset := 0
+	pageSize := historyFetchPageSize
+	if filter.isEmpty() {
+		offset = skip
+		skip = 0
+		pageSize = limit
+	}
+
+	entries := []historyEntry{}
+	err = forEachCommit(projectConfig, offset, pageSize, func(commit models.CommitWithBranch) (bool, error) {
+		// Commits are sorted newest first, so no further commits can match
+		if !filter.Since.IsZero() && commit.CreatedAt.Before(filter.Since) {
+			return false, nil
+		}
+
+		entry, matched, err := filterHistoryCommit(commit, filter)
+		if err != nil || !matched {
+			return true, err
+		}
+
+		if skip > 0 {
+			skip--
+			return true, nil
+		}
+
+		entry.Current = commit.Index == projectConfig.CurrentCommitIndex
+		entries = append(entries, entry)
+		return len(entries) < limit, nil
+	})
 	if err != nil {
 		return err
 	}
 
 	// Print commits
-	for _, c := range commits {
-		createdAt := c.CreatedAt.Format(time.RFC1123)
-		fmt.Printf("%s "+color.InCyan(color.InBold("[%s; #%d]"))+" %s\n", createdAt, c.Branch.Name, c.Index, c.Message)
+	if c.Bool("json") {
+		entriesJson, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(entriesJson))
+		return nil
+	}
+
+	if len(entries) == 0 {
+		console.Info("No commits found")
+		return nil
+	}
+
+	for _, e := range entries {
+		if tmpl != nil {
+			if err = tmpl.Execute(os.Stdout, e); err != nil {
+				return console.Error("Failed to format commit #%d: %v", e.Index, err)
+			}
+			fmt.Println()
+			continue
+		}
+
+		createdAt := e.Date.Format(time.RFC1123)
+		current := ""
+		if e.Current {
+			current = color.InGreen(" (current)")
+		}
+		fmt.Printf("%s "+color.InCyan(color.InBold("[%s; #%d]"))+" %s "+color.InGray("(%s)")+"%s\n", createdAt, e.Branch, e.Index, e.Message, e.Author, current)
 	}
 
 	return nil
 }
+
+// Call `fn` for each commit across all branches, newest first, starting `offset` commits in and
+// fetching `pageSize` commits from the server at a time. Stops once `fn` returns false or an error,
+// or once the server stops returning older commits.
+func forEachCommit(projectConfig models.ProjectConfig, offset int, pageSize int, fn func(commit models.CommitWithBranch) (bool, error)) error {
+	lowestIndex := math.MaxInt
+	for ; ; offset += pageSize {
+		commits, err := vcs.GetCommits(projectConfig, pageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		// Guard against paging forever if the server doesn't support offsets
+		pageLowestIndex := math.MaxInt
+		for _, commit := range commits {
+			if commit.Index < pageLowestIndex {
+				pageLowestIndex = commit.Index
+			}
+		}
+		if len(commits) > 0 && pageLowestIndex >= lowestIndex {
+			console.Warning("Stopped listing commits since the server returned a page with no older ones; history may be incomplete")
+			return nil
+		}
+
+		for _, commit := range commits {
+			// Skip commits already seen, in case pages overlap
+			if commit.Index >= lowestIndex {
+				continue
+			}
+
+			cont, err := fn(commit)
+			if err != nil || !cont {
+				return err
+			}
+		}
+
+		if len(commits) < pageSize {
+			return nil
+		}
+
+		lowestIndex = pageLowestIndex
+	}
+}
+
+// Whether the history filter matches every commit.
+func (f historyFilter) isEmpty() bool {
+	return f.Branch == "" && f.Author == "" && f.Since.IsZero() && f.Until.IsZero() && f.Message == "" && len(f.Paths) == 0
+}
+
+// Check whether a commit matches the history filter.
+// Returns the history entry for the commit if it does.
+func filterHistoryCommit(commit models.CommitWithBranch, filter historyFilter) (historyEntry, bool, error) {
+	if filter.Branch != "" && commit.Branch.Name != filter.Branch {
+		return historyEntry{}, false, nil
+	}
+
+	if !filter.Until.IsZero() && commit.CreatedAt.After(filter.Until) {
+		return historyEntry{}, false, nil
+	}
+
+	if filter.Message != "" && !strings.Contains(strings.ToLower(commit.Message), filter.Message) {
+		return historyEntry{}, false, nil
+	}
+
+	if len(filter.Paths) > 0 {
+		touched := false
+		for _, paths := range [][]string{commit.CreatedFiles, commit.ModifiedFiles, commit.DeletedFiles} {
+			for _, path := range paths {
+				if vcs.PathMatches(path, filter.Paths) {
+					touched = true
+					break
+				}
+			}
+		}
+
+		if !touched {
+			return historyEntry{}, false, nil
+		}
+	}
+
+	authorName, err := vcs.GetUserName(commit.AuthorID)
+	if err != nil {
+		return historyEntry{}, false, err
+	}
+
+	if filter.Author != "" && !strings.EqualFold(commit.AuthorID, filter.Author) && !strings.Contains(strings.ToLower(authorName), filter.Author) {
+		return historyEntry{}, false, nil
+	}
+
+	entry := historyEntry{
+		Index:         commit.Index,
+		Branch:        commit.Branch.Name,
+		Author:        authorName,
+		Date:          commit.CreatedAt,
+		Message:       commit.Message,
+		CreatedFiles:  commit.CreatedFiles,
+		ModifiedFiles: commit.ModifiedFiles,
+		DeletedFiles:  commit.DeletedFiles,
+	}
+
+	return entry, true, nil
+}
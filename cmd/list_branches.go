@@ -1,18 +1,12 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
 
 	"github.com/TwiN/go-color"
-	"github.com/decentvcs/cli/config"
 	"github.com/decentvcs/cli/constants"
 	"github.com/decentvcs/cli/lib/auth"
-	"github.com/decentvcs/cli/lib/console"
-	"github.com/decentvcs/cli/lib/httpvalidation"
 	"github.com/decentvcs/cli/lib/vcs"
-	"github.com/decentvcs/cli/models"
 	"github.com/urfave/cli/v2"
 )
 
@@ -27,29 +21,30 @@ func ListBranches(c *cli.Context) error {
 	}
 
 	// Get all branches in project
-	httpClient := http.Client{}
-	reqUrl := fmt.Sprintf("%s/projects/%s/branches?join_commit=true", config.I.VCS.ServerHost, projectConfig.ProjectSlug)
-	req, err := http.NewRequest("GET", reqUrl, nil)
+	branches, err := vcs.GetBranches(projectConfig)
 	if err != nil {
 		return err
 	}
-	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
-	res, err := httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	if err = httpvalidation.ValidateResponse(res); err != nil {
-		return err
-	}
-	defer res.Body.Close()
 
-	// Parse branches
-	var branches []models.BranchWithCommit
-	err = json.NewDecoder(res.Body).Decode(&branches)
-	if err != nil {
-		return console.Error(constants.ErrInternal)
+	// Branches are compared against the current branch, and the current branch against the default
+	// one
+	var defaultBranchName string
+	if c.Bool("verbose") {
+		project, err := vcs.GetProject(projectConfig)
+		if err != nil {
+			return err
+		}
+
+		for _, branch := range branches {
+			if branch.ID == project.DefaultBranchID {
+				defaultBranchName = branch.Name
+			}
+		}
 	}
 
+	// Shared between branches so the commits of the compared branch are only fetched once
+	branchCache := vcs.NewBranchCache(projectConfig)
+
 	// Print branches
 	for _, branch := range branches {
 		isCurrentBranch := projectConfig.CurrentBranchName == branch.Name
@@ -62,6 +57,34 @@ func ListBranches(c *cli.Context) error {
 		}
 
 		fmt.Printf(branchNameFmt+" commit #%d\n", branch.Commit.Index)
+
+		if !c.Bool("verbose") {
+			continue
+		}
+
+		authorName, err := vcs.GetUserName(branch.Commit.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("  Last commit by %s on %s\n", authorName, branch.Commit.CreatedAt.Local().Format(constants.TimeFormat))
+
+		compareName := projectConfig.CurrentBranchName
+		if isCurrentBranch {
+			compareName = defaultBranchName
+		}
+		if compareName == "" || compareName == branch.Name {
+			continue
+		}
+
+		// Don't fail the whole listing because of branches created before fork points were recorded
+		divergence, err := branchCache.GetBranchDivergence(branch.Name, compareName)
+		if err != nil {
+			fmt.Printf("  Cannot compare with %s: %v\n", compareName, err)
+			continue
+		}
+
+		fmt.Printf("  %d ahead, %d behind %s (forked at commit #%d)\n", len(divergence.Ours), len(divergence.Theirs), compareName, divergence.Base.Index)
 	}
 
 	return nil
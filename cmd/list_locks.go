@@ -12,7 +12,6 @@ import (
 	"github.com/decentvcs/cli/lib/vcs"
 	"github.com/decentvcs/cli/models"
 	"github.com/samber/lo"
-	"github.com/stytchauth/stytch-go/v5/stytch"
 	"github.com/urfave/cli/v2"
 )
 
@@ -60,32 +59,10 @@ func ListLocks(c *cli.Context) error {
 	lockedByUserIDs := lo.Values(branch.Locks)
 	lockedByUserNames := make(map[string]string)
 	for _, userID := range lockedByUserIDs {
-		// Get Stytch user from server
-		reqUrl = fmt.Sprintf("%s/stytch/users/%s", config.I.VCS.ServerHost, userID)
-		req, _ = http.NewRequest("GET", reqUrl, nil)
-		req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
-		res, err = httpClient.Do(req)
+		lockedByUserNames[userID], err = vcs.GetUserName(userID)
 		if err != nil {
 			return err
 		}
-		if err = httpvalidation.ValidateResponse(res); err != nil {
-			return err
-		}
-
-		// Parse response
-		var stytchUser stytch.UsersGetResponse
-		err = json.NewDecoder(res.Body).Decode(&stytchUser)
-		if err != nil {
-			return err
-		}
-
-		if stytchUser.Name.FirstName != "" || stytchUser.Name.LastName != "" {
-			// Use name
-			lockedByUserNames[userID] = stytchUser.Name.FirstName + " " + stytchUser.Name.LastName
-		} else {
-			// Use first email
-			lockedByUserNames[userID] = stytchUser.Emails[0].Email
-		}
 	}
 
 	// Print locks
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/TwiN/go-color"
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/urfave/cli/v2"
+)
+
+// List recovery snapshots of local files replaced by destructive operations.
+func ListRecoverySnapshots(c *cli.Context) error {
+	snapshots, err := vcs.ListRecoverySnapshots()
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) == 0 {
+		console.Info("No recovery snapshots found")
+		return nil
+	}
+
+	for _, snapshot := range snapshots {
+		fmt.Printf(
+			color.InCyan(color.InBold("%s"))+" %s (%s on commit #%d, %d files)\n",
+			snapshot.ID,
+			snapshot.CreatedAt.Format(constants.TimeFormat),
+			snapshot.Operation,
+			snapshot.CommitIndex,
+			len(snapshot.Files),
+		)
+
+		if c.Bool("files") {
+			for _, path := range snapshot.Files {
+				fmt.Printf("  %s\n", path)
+			}
+		}
+	}
+
+	return nil
+}
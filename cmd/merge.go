@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/TwiN/go-color"
 	"github.com/decentvcs/cli/config"
 	"github.com/decentvcs/cli/constants"
 	"github.com/decentvcs/cli/lib/auth"
@@ -21,6 +21,7 @@ import (
 	"github.com/decentvcs/cli/models"
 	"github.com/urfave/cli/v2"
 	"github.com/xyproto/binary"
+	"golang.org/x/exp/maps"
 )
 
 // Merge the specified branch into the current branch.
@@ -31,8 +32,8 @@ func Merge(c *cli.Context) error {
 	auth.HasToken()
 
 	// Extract args
-	branchName := c.Args().Get(0)
-	if branchName == "" {
+	revision := c.Args().Get(0)
+	if revision == "" {
 		return console.Error("Please specify name of branch to merge")
 	}
 
@@ -75,64 +76,118 @@ func Merge(c *cli.Context) error {
 		return err
 	}
 
-	// Get specified branch w/ commit
-	reqUrl = fmt.Sprintf("%s/projects/%s/branches/%s?join_commit=true", config.I.VCS.ServerHost, projectConfig.ProjectSlug, branchName)
-	req, err = http.NewRequest("GET", reqUrl, nil)
+	// Get commit to merge, usually the latest commit on a branch
+	commitToMerge, err := vcs.ResolveRevision(projectConfig, revision)
 	if err != nil {
 		return err
 	}
-	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
-	res, err = httpClient.Do(req)
+
+	branch, err := vcs.GetBranch(projectConfig, commitToMerge.BranchID)
 	if err != nil {
 		return err
 	}
-	if err = httpvalidation.ValidateResponse(res); err != nil {
-		return err
+
+	branchToMerge := models.BranchWithCommit{
+		ID:        branch.ID,
+		CreatedAt: branch.CreatedAt,
+		Name:      branch.Name,
+		ProjectID: branch.ProjectID,
+		Commit:    commitToMerge,
+		Locks:     branch.Locks,
 	}
-	defer res.Body.Close()
 
-	// Parse response
-	var branchToMerge models.BranchWithCommit
-	err = json.NewDecoder(res.Body).Decode(&branchToMerge)
+	// Find the commit both branches diverged from, used to tell deletions apart from new files
+	divergence, err := vcs.GetBranchDivergence(projectConfig, currentBranch.Name, branchToMerge.Name)
 	if err != nil {
-		return err
+		// Without a base, deletions and changes on both sides can't be told apart
+		console.ErrorPrint("%v", err)
+		return console.Error("Cannot merge \"%s\" safely. Take the files you need from it with `dvcs restore -b %s [paths...]` and push them instead.", branchToMerge.Name, branchToMerge.Name)
 	}
 
+	baseFiles := divergence.Base.Files
+
 	// Detect movable files, which will simply be moved to the local project, overriding the current
 	// versions.
 	mvHashMap := make(map[string]string)
+	conflicts := []vcs.Conflict{}
 	for path, f := range branchToMerge.Commit.Files {
-		if _, ok := localHashMap[path]; !ok {
-			mvHashMap[path] = f.Hash
+		if _, ok := localHashMap[path]; ok {
+			continue
+		}
+
+		if baseFile, ok := baseFiles[path]; ok {
+			// File was deleted locally or on the current branch
+			if baseFile.Hash != f.Hash {
+				// Restore their version so the user can decide whether to keep it
+				conflicts = append(conflicts, vcs.Conflict{Path: path, Reason: fmt.Sprintf("deleted locally, modified in \"%s\"", branchToMerge.Name)})
+				mvHashMap[path] = f.Hash
+			}
+			continue
 		}
+
+		mvHashMap[path] = f.Hash
 	}
 
-	// Detect mergable files
+	// Detect mergable files and files deleted in the other branch
 	mergeHashMap := make(map[string]string)
+	filesToDelete := []string{}
 	for path, hash := range localHashMap {
-		newHash := branchToMerge.Commit.Files[path].Hash
-		if hash != newHash {
-			// Check if file is binary data
-			isBinary, err := binary.File(path)
-			if err != nil {
-				return err
+		theirFile, ok := branchToMerge.Commit.Files[path]
+		if !ok {
+			baseFile, inBase := baseFiles[path]
+			if !inBase {
+				// File only exists locally, keep it
+				continue
 			}
 
-			if isBinary {
-				// Binary, file cannot be merged
-				mvHashMap[path] = newHash
+			if hash == baseFile.Hash {
+				// Unchanged locally, apply deletion
+				filesToDelete = append(filesToDelete, path)
 			} else {
-				// Non-binary, file can be merged
-				mergeHashMap[path] = newHash
+				// Keep local version so no work is lost
+				conflicts = append(conflicts, vcs.Conflict{Path: path, Reason: fmt.Sprintf("modified locally, deleted in \"%s\"", branchToMerge.Name)})
 			}
+			continue
+		}
+
+		newHash := theirFile.Hash
+		if hash == newHash {
+			continue
+		}
+
+		baseFile, inBase := baseFiles[path]
+
+		// Skip files that were only changed locally
+		if inBase && baseFile.Hash == newHash {
+			continue
+		}
+
+		// Take their version of files that were only changed in the other branch
+		if inBase && baseFile.Hash == hash {
+			mvHashMap[path] = newHash
+			continue
+		}
+
+		// Check if file is binary data
+		isBinary, err := binary.File(path)
+		if err != nil {
+			return err
+		}
+
+		if isBinary {
+			// Binary, file cannot be merged, so keep local version so no work is lost
+			conflicts = append(conflicts, vcs.Conflict{Path: path, Reason: fmt.Sprintf("binary file changed both locally and in \"%s\"", branchToMerge.Name)})
+		} else {
+			// Non-binary, file can be merged
+			mergeHashMap[path] = newHash
 		}
 	}
 
 	combinedHashMap := util.MergeMaps(mvHashMap, mergeHashMap)
 
 	// Return if no changes detected
-	if len(combinedHashMap) == 0 {
-		console.Warning("Local changes and branch \"%s\" are equivalent, aborting merge.", branchName)
+	if len(combinedHashMap) == 0 && len(filesToDelete) == 0 && len(conflicts) == 0 {
+		console.Warning("Local changes and branch \"%s\" are equivalent, aborting merge.", branchToMerge.Name)
 		return nil
 	}
 
@@ -144,13 +199,44 @@ func Merge(c *cli.Context) error {
 	// - mergable files
 	//
 	// NOTE: Downloaded files are already decompressed
-	console.Info("Downloading required files...")
 	console.Verbose("Temp directory: %s", tempDirPath)
-	err = storage.DownloadMany(projectConfig, tempDirPath, combinedHashMap)
+	if len(combinedHashMap) > 0 {
+		console.Info("Downloading required files...")
+		err = storage.DownloadMany(projectConfig, tempDirPath, combinedHashMap)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Download the base versions of mergable files
+	baseDirPath, err := os.MkdirTemp(tempDirPath, "merge-base-")
 	if err != nil {
 		return err
 	}
 
+	baseHashMap := make(map[string]string)
+	for path := range mergeHashMap {
+		if baseFile, ok := baseFiles[path]; ok {
+			baseHashMap[path] = baseFile.Hash
+		}
+	}
+
+	if len(baseHashMap) > 0 {
+		err = storage.DownloadManyCached(projectConfig, baseDirPath, baseHashMap)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Print deletions and conflicts so they can be reviewed before confirming
+	if len(filesToDelete) > 0 {
+		console.Info("Files deleted in \"%s\":", branchToMerge.Name)
+		for _, path := range filesToDelete {
+			fmt.Printf(color.InRed("  - %s\n"), path)
+		}
+	}
+	printConflicts(conflicts)
+
 	// Prompt user to confirm merge
 	if confirm {
 		console.Warning("Merge \"%s\" into \"%s\" (current)? (y/n)", branchToMerge.Name, currentBranch.Name)
@@ -171,31 +257,65 @@ func Merge(c *cli.Context) error {
 		}
 	}
 
-	// Create empty base file for three-way merge
-	baseFilePath := filepath.Join(tempDirPath, "empty")
-	err = os.WriteFile(baseFilePath, []byte{}, 0644)
+	// Back up local changes that are about to be replaced
+	currentCommit, err := vcs.GetCommit(projectConfig, projectConfig.CurrentCommitIndex)
+	if err != nil {
+		return err
+	}
+
+	_, err = vcs.BackupFiles(projectConfig, "merge", append(maps.Keys(combinedHashMap), filesToDelete...), currentCommit.Files)
+	if err != nil {
+		return err
+	}
+
+	// Files created on both sides are merged against an empty base
+	emptyFilePath := filepath.Join(baseDirPath, "empty")
+	err = os.WriteFile(emptyFilePath, []byte{}, 0644)
 	if err != nil {
 		return console.Error("Failed to create base file: %s", err)
 	}
 
-	// Move created files to project dir
-	console.Verbose("Moving %d files to project...", len(mvHashMap))
+	// Files that will be moved into the project dir
+	files := make(map[string]string)
 	for path := range mvHashMap {
-		dlPath := filepath.Join(tempDirPath, path)
-		err = os.Rename(dlPath, path)
-		if err != nil {
-			return err
-		}
+		files[path] = filepath.Join(tempDirPath, path)
 	}
 
+	// Merge into copies of the local files
 	console.Verbose("Merging %d files...", len(mergeHashMap))
+	mergeConflicts := []vcs.Conflict{}
 	for path := range mergeHashMap {
 		dlPath := filepath.Join(tempDirPath, path)
-		cmd := exec.Command("git", "merge-file", path, baseFilePath, dlPath, "--union")
-		err := cmd.Run()
+		mergedPath := dlPath + ".merged"
+		err = system.CopyFile(path, mergedPath)
 		if err != nil {
-			return console.Error("Failed to merge file \"%s\": %v", path, err)
+			return err
+		}
+
+		basePath := emptyFilePath
+		if _, ok := baseHashMap[path]; ok {
+			basePath = filepath.Join(baseDirPath, path)
 		}
+
+		conflicted, err := vcs.MergeTextFile(mergedPath, basePath, dlPath, branchToMerge.Name)
+		if err != nil {
+			return err
+		}
+		if conflicted {
+			mergeConflicts = append(mergeConflicts, vcs.Conflict{Path: path, Reason: fmt.Sprintf("changed both locally and in \"%s\"; resolve the conflict markers", branchToMerge.Name)})
+		}
+
+		files[path] = mergedPath
+	}
+
+	// Apply merged, moved, and deleted files to project dir
+	err = vcs.ApplyWorkingCopyUpdate(projectConfig, vcs.WorkingCopyUpdate{
+		Operation: "merge",
+		Files:     files,
+		Deletes:   filesToDelete,
+	})
+	if err != nil {
+		return err
 	}
 
 	// Delete temp dir
@@ -205,6 +325,16 @@ func Merge(c *cli.Context) error {
 		return err
 	}
 
+	printConflicts(mergeConflicts)
+	conflicts = append(conflicts, mergeConflicts...)
+	if len(conflicts) > 0 {
+		console.Warning("Merged with %d conflicts. Resolve them before pushing.", len(conflicts))
+		if push {
+			return console.Error("Not pushing since the merge has conflicts")
+		}
+		return nil
+	}
+
 	// Push if `push` flag provided (after user confirmation)
 	// (This will also push local changes)
 	if push {
@@ -214,3 +344,15 @@ func Merge(c *cli.Context) error {
 
 	return nil
 }
+
+// Print conflicts, if any.
+func printConflicts(conflicts []vcs.Conflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+
+	console.Warning("Conflicts:")
+	for _, c := range conflicts {
+		console.Warning("  %s (%s)", c.Path, c.Reason)
+	}
+}
@@ -1,20 +1,12 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
 	"path/filepath"
 	"regexp"
 
-	"github.com/decentvcs/cli/config"
-	"github.com/decentvcs/cli/constants"
 	"github.com/decentvcs/cli/lib/auth"
 	"github.com/decentvcs/cli/lib/console"
-	"github.com/decentvcs/cli/lib/httpvalidation"
 	"github.com/decentvcs/cli/lib/vcs"
-	"github.com/decentvcs/cli/models"
 	"github.com/urfave/cli/v2"
 )
 
@@ -40,41 +32,32 @@ func NewBranch(c *cli.Context) error {
 		return err
 	}
 
-	// Create branch
-	bodyJson, err := json.Marshal(models.BranchCreateDTO{
-		Name:        branchName,
-		CommitIndex: projectConfig.CurrentCommitIndex,
-	})
-	if err != nil {
+	if err = ensureNoTagNamed(projectConfig, branchName); err != nil {
 		return err
 	}
 
-	httpClient := http.Client{}
-	reqUrl := fmt.Sprintf("%s/projects/%s/branches", config.I.VCS.ServerHost, projectConfig.ProjectSlug)
-	req, err := http.NewRequest("POST", reqUrl, bytes.NewBuffer(bodyJson))
+	// Get commit to create branch from
+	fromCommit, err := vcs.ResolveRevision(projectConfig, c.String("from"))
 	if err != nil {
 		return err
 	}
-	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
-	req.Header.Set("Content-Type", "application/json")
-	res, err := httpClient.Do(req)
+
+	// Create branch
+	branch, err := vcs.CreateBranch(projectConfig, branchName, fromCommit.Index)
 	if err != nil {
 		return err
 	}
-	if err = httpvalidation.ValidateResponse(res); err != nil {
-		return err
-	}
-	defer res.Body.Close()
 
-	// Parse response
-	var branch models.Branch
-	err = json.NewDecoder(res.Body).Decode(&branch)
-	if err != nil {
-		return err
+	// Merges and comparisons use the fork point as the base, and refuse to guess without it
+	if branch.ForkCommitIndex != fromCommit.Index {
+		console.Warning("The server didn't record that branch %s was created from commit #%d; it can't be merged or compared with other branches", branch.Name, fromCommit.Index)
 	}
 
 	// Set current branch
+	previousBranchName := projectConfig.CurrentBranchName
+	previousDetached := projectConfig.Detached
 	projectConfig.CurrentBranchName = branch.Name
+	projectConfig.Detached = false
 	projectConfigPath, err := vcs.GetProjectConfigPath()
 	if err != nil {
 		return err
@@ -84,6 +67,30 @@ func NewBranch(c *cli.Context) error {
 		return err
 	}
 
+	// Sync to the commit the branch was created from
+	if fromCommit.Index != projectConfig.CurrentCommitIndex {
+		_, err = vcs.SyncToCommit(projectConfig, fromCommit.Index, !c.Bool("yes"))
+		if err != nil {
+			return err
+		}
+
+		projectConfig, err = vcs.GetProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		if projectConfig.CurrentCommitIndex != fromCommit.Index {
+			// Stay on the previous branch since the working copy wasn't updated
+			projectConfig.CurrentBranchName = previousBranchName
+			projectConfig.Detached = previousDetached
+			if _, err = vcs.SaveProjectConfig(filepath.Dir(projectConfigPath), projectConfig); err != nil {
+				return err
+			}
+
+			return console.Error("Created branch %s, but didn't switch to it since syncing to commit #%d was aborted. Run `dvcs branch use %s` to switch to it.", branch.Name, fromCommit.Index, branch.Name)
+		}
+	}
+
 	console.Info("Created and switched to branch %s", branch.Name)
 	return nil
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/decentvcs/cli/config"
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/urfave/cli/v2"
+)
+
+// Permanently delete objects from storage that are no longer referenced by any commit or shelf
+// (e.g. after amending).
+func Purge(c *cli.Context) error {
+	auth.HasToken()
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	// Prompt user for confirmation
+	if !c.Bool("yes") {
+		console.Warning("This will permanently delete all stored files that aren't used by any commit or shelf and are older than %d days.", config.I.VCS.Storage.PurgeRetentionDays)
+		console.Warning("Files replaced by amended commits can no longer be recovered afterwards. Continue? (y/n)")
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			console.Info("Aborted")
+			return nil
+		}
+	}
+
+	if err = vcs.PurgeUnusedObjects(projectConfig); err != nil {
+		return err
+	}
+
+	console.Success("Unused objects deleted")
+	return nil
+}
@@ -43,16 +43,16 @@ func WithNoConfirm() func(*PushOptions) {
 func Push(c *cli.Context, opts ...func(*PushOptions)) error {
 	auth.HasToken()
 
+	if c.Bool("amend") {
+		return Amend(c)
+	}
+
 	// Build options
 	o := &PushOptions{
 		Message: c.String("message"),
 		Confirm: !c.Bool("yes"),
 	}
 
-	if o.Message == "" {
-		o.Message = "No message"
-	}
-
 	for _, opt := range opts {
 		opt(o)
 	}
@@ -63,6 +63,28 @@ func Push(c *cli.Context, opts ...func(*PushOptions)) error {
 		return err
 	}
 
+	if err = ensureNotDetached(projectConfig); err != nil {
+		return err
+	}
+
+	// Only push files in the changelist, if specified
+	var changelist *models.Changelist
+	if c.String("changelist") != "" {
+		cl, err := vcs.GetChangelist(c.String("changelist"))
+		if err != nil {
+			return err
+		}
+		if len(cl.Paths) == 0 {
+			return console.Error("Changelist \"%s\" is empty", cl.Name)
+		}
+
+		changelist = &cl
+
+		if o.Message == "" {
+			o.Message = cl.Description
+		}
+	}
+
 	// Get current branch w/ latest commit
 	httpClient := http.Client{}
 	reqUrl := fmt.Sprintf(
@@ -126,13 +148,31 @@ func Push(c *cli.Context, opts ...func(*PushOptions)) error {
 	// Make sure user is synced with remote before continuing
 	if currentBranch.Commit.Index != projectConfig.CurrentCommitIndex {
 		if force {
+			// List the commits that will be lost
+			branchCommits, err := vcs.GetBranchCommits(projectConfig, currentBranch.ID)
+			if err != nil {
+				return err
+			}
+
 			console.Warning("You're about to force push!")
 			console.Warning(
-				"This will permanently delete all commits and new files ahead of your current commit (#%d) on this "+
-					"branch (%s).",
+				"This will remove all commits ahead of your current commit (#%d) from this branch (%s):",
 				projectConfig.CurrentCommitIndex,
 				currentBranch.Name,
 			)
+			for _, lostCommit := range branchCommits {
+				if lostCommit.Index <= projectConfig.CurrentCommitIndex {
+					continue
+				}
+
+				authorName, err := vcs.GetUserName(lostCommit.AuthorID)
+				if err != nil {
+					return err
+				}
+
+				console.Warning("  #%d by %s: %s", lostCommit.Index, authorName, lostCommit.Message)
+			}
+			console.Warning("They will be moved to a new backup branch.")
 			console.Warning("Continue? (y/n)")
 
 			var answer string
@@ -142,18 +182,57 @@ func Push(c *cli.Context, opts ...func(*PushOptions)) error {
 				return nil
 			}
 		} else {
-			console.ErrorPrint(
+			console.Warning(
 				"You're on commit #%d, but the remote branch points to commit #%d.",
 				projectConfig.CurrentCommitIndex,
 				currentBranch.Commit.Index,
 			)
-			return console.Error("You can forcefully push your changes by using the --force flag.")
+
+			// Offer to sync to the latest commit first, carrying local changes forward
+			rebase := c.Bool("rebase")
+			if !rebase && o.Confirm {
+				console.Warning("Sync to commit #%d first, keeping your local changes? (y/n)", currentBranch.Commit.Index)
+				var answer string
+				fmt.Scanln(&answer)
+				rebase = answer == "y"
+			}
+
+			if !rebase {
+				return console.Error("Sync to the latest commit with --rebase, or forcefully push your changes with --force.")
+			}
+
+			syncRes, err := vcs.SyncToCommit(projectConfig, 0, o.Confirm)
+			if err != nil {
+				return err
+			}
+			if !syncRes.Synced {
+				return console.Error("Not pushing since sync was aborted")
+			}
+			if len(syncRes.Conflicts) > 0 {
+				return console.Error("Not pushing since there are merge conflicts. Resolve them and push again.")
+			}
+
+			// Continue pushing on top of the commit that was synced to
+			projectConfig, err = vcs.GetProjectConfig()
+			if err != nil {
+				return err
+			}
+
+			currentCommit, err = vcs.GetCommit(projectConfig, projectConfig.CurrentCommitIndex)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	// Detect local changes
 	startTime := time.Now()
-	fc, err := vcs.DetectFileChanges(currentCommit.Files)
+	detectOpts := []func(*vcs.DetectFileChangesOptions){}
+	if changelist != nil {
+		detectOpts = append(detectOpts, vcs.WithPaths(changelist.Paths))
+	}
+
+	fc, err := vcs.DetectFileChanges(currentCommit.Files, detectOpts...)
 	if err != nil {
 		return err
 	}
@@ -167,9 +246,38 @@ func Push(c *cli.Context, opts ...func(*PushOptions)) error {
 		return nil
 	}
 
+	// Get commit message, letting the user write one in their editor if none was specified
+	commitConfig, err := vcs.GetCommitConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.Message == "" && o.Confirm && system.IsTerminal() {
+		o.Message, err = vcs.EditCommitMessage(commitConfig, currentBranch.Name, fc)
+		if err != nil {
+			return err
+		}
+		if o.Message == "" {
+			console.Info("Aborted due to empty commit message")
+			return nil
+		}
+	}
+
+	if o.Message == "" {
+		o.Message = "No message"
+	}
+
+	if err = vcs.ValidateCommitMessage(commitConfig, o.Message); err != nil {
+		return err
+	}
+
 	// Prompt user for confirmation
 	if o.Confirm {
-		console.Warning("Push these changes to \"%s\" branch? (y/n)", currentBranch.Name)
+		if changelist != nil {
+			console.Warning("Push these changes from changelist \"%s\" to \"%s\" branch? (y/n)", changelist.Name, currentBranch.Name)
+		} else {
+			console.Warning("Push these changes to \"%s\" branch? (y/n)", currentBranch.Name)
+		}
 		var answer string
 		fmt.Scanln(&answer)
 		if answer != "y" {
@@ -200,12 +308,20 @@ func Push(c *cli.Context, opts ...func(*PushOptions)) error {
 		return err
 	}
 
-	if force {
+	if force && currentBranch.Commit.Index != currentCommit.Index {
 		// User is force pushing.
-		// Delete commits ahead of current commit.
-		if err = vcs.DeleteCommitsAheadOfIndex(projectConfig, currentBranch.ID, currentCommit.Index); err != nil {
+		// Move commits ahead of the current commit to a backup branch created from it, so they stay
+		// restorable and their objects remain in use.
+		backupBranchName := fmt.Sprintf("%s-backup-%s", currentBranch.Name, time.Now().Format("20060102-150405"))
+		backupBranch, err := vcs.CreateBranch(projectConfig, backupBranchName, currentCommit.Index)
+		if err != nil {
+			return console.Error("Failed to create backup branch, aborting force push: %v", err)
+		}
+
+		if err = vcs.MoveCommitsAheadOfIndex(projectConfig, currentBranch.ID, currentCommit.Index, backupBranch.ID); err != nil {
 			return err
 		}
+		console.Info("Moved commits ahead of #%d to backup branch \"%s\"", currentCommit.Index, backupBranch.Name)
 	}
 
 	startTime = time.Now()
@@ -317,10 +433,17 @@ func Push(c *cli.Context, opts ...func(*PushOptions)) error {
 	if err = httpvalidation.ValidateResponse(res); err != nil {
 		return err
 	}
-	res.Body.Close() // close immediately since we dont need it
+	defer res.Body.Close()
 
-	// Bump current commit index to reflect new commit
-	projectConfig.CurrentCommitIndex++
+	// Parse created commit. Its index isn't necessarily the next one after the current commit, e.g.
+	// if a force push moved commits ahead of it to a backup branch.
+	var newCommit models.Commit
+	err = json.NewDecoder(res.Body).Decode(&newCommit)
+	if err != nil {
+		return console.Error("Failed to parse commit: %v", err)
+	}
+
+	projectConfig.CurrentCommitIndex = newCommit.Index
 
 	console.Verbose("Commit #%d created successfully", projectConfig.CurrentCommitIndex)
 	console.Verbose("Updating current commit index in project config...")
@@ -335,7 +458,24 @@ func Push(c *cli.Context, opts ...func(*PushOptions)) error {
 		return err
 	}
 
+	// Changelist was submitted, so it's no longer needed
+	if changelist != nil {
+		if err = vcs.DeleteChangelist(changelist.Name); err != nil {
+			return err
+		}
+	}
+
 	timeElapsed = time.Since(startTime).Truncate(time.Microsecond)
 	console.Success("Commit #%d pushed in %s", projectConfig.CurrentCommitIndex, timeElapsed)
 	return nil
 }
+
+// Make sure the working copy isn't in a detached state, where commits would end up on a branch
+// the current commit doesn't belong to.
+func ensureNotDetached(projectConfig models.ProjectConfig) error {
+	if projectConfig.Detached {
+		return console.Error("You're on commit #%d, which is not on branch \"%s\". Create a branch for your changes with `dvcs branch new <name>` first.", projectConfig.CurrentCommitIndex, projectConfig.CurrentBranchName)
+	}
+
+	return nil
+}
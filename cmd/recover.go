@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/urfave/cli/v2"
+)
+
+// Restore local files from a recovery snapshot.
+func Recover(c *cli.Context) error {
+	// Get args
+	snapshotID := c.Args().First()
+	if snapshotID == "" {
+		return console.Error("Please specify a recovery snapshot ID (run `dvcs recover list` to list them)")
+	}
+
+	paths := c.Args().Tail()
+
+	// Get project config, implicitly making sure current directory is a project
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := vcs.GetRecoverySnapshot(snapshotID)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		paths = snapshot.Files
+	}
+
+	if err = vcs.ValidateRecoveryPaths(paths); err != nil {
+		return err
+	}
+
+	// Prompt user for confirmation
+	if !c.Bool("yes") {
+		console.Warning("The following files will be overwritten with their versions from %s:", snapshot.ID)
+		for _, path := range paths {
+			console.Warning("  %s", path)
+		}
+		console.Warning("Continue? (y/n)")
+
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			console.Info("Aborted")
+			return nil
+		}
+	}
+
+	// Back up current versions in case the wrong snapshot was restored
+	projectConfigPath, err := vcs.GetProjectConfigPath()
+	if err != nil {
+		return err
+	}
+
+	backupPaths := []string{}
+	for _, path := range paths {
+		backupPaths = append(backupPaths, filepath.Join(filepath.Dir(projectConfigPath), path))
+	}
+
+	_, err = vcs.BackupFiles(projectConfig, "recover", backupPaths, nil)
+	if err != nil {
+		return err
+	}
+
+	err = vcs.RestoreRecoverySnapshot(snapshot, paths)
+	if err != nil {
+		return err
+	}
+
+	console.Success("Restored %d files from %s", len(paths), snapshot.ID)
+	return nil
+}
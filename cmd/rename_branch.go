@@ -39,6 +39,10 @@ func RenameBranch(c *cli.Context) error {
 		return err
 	}
 
+	if err = ensureNoTagNamed(projectConfig, newName); err != nil {
+		return err
+	}
+
 	// Get specified branch (for validation purposes)
 	httpClient := http.Client{}
 	reqUrl := fmt.Sprintf("%s/projects/%s/branches/%s", config.I.VCS.ServerHost, projectConfig.ProjectSlug, oldName)
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/storage"
+	"github.com/decentvcs/cli/lib/system"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/decentvcs/cli/models"
+	"github.com/urfave/cli/v2"
+)
+
+// Restore individual files from a commit
+func Restore(c *cli.Context) error {
+	auth.HasToken()
+
+	// Parse args
+	if c.NArg() == 0 {
+		return console.Error("Please specify the paths of the files to restore")
+	}
+
+	if c.IsSet("commit") && c.IsSet("branch") {
+		return console.Error("Please specify either a commit or a branch, not both")
+	}
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	projectConfigPath, err := vcs.GetProjectConfigPath()
+	if err != nil {
+		return err
+	}
+
+	projectPath := filepath.Dir(projectConfigPath)
+
+	// Get commit to restore files from
+	var commit models.Commit
+	revision := "head"
+	if c.IsSet("commit") {
+		revision = c.String("commit")
+	} else if c.IsSet("branch") {
+		revision = c.String("branch")
+	}
+
+	commit, err = vcs.ResolveRevision(projectConfig, revision)
+	if err != nil {
+		return err
+	}
+
+	// Find files in commit matching paths
+	filters, err := getPathFilters(projectPath, c.Args().Slice())
+	if err != nil {
+		return err
+	}
+
+	hashMap := make(map[string]string)
+	for _, filter := range filters {
+		matched := false
+		for path, fileData := range commit.Files {
+			if vcs.PathMatches(path, []string{filter}) {
+				hashMap[path] = fileData.Hash
+				matched = true
+			}
+		}
+
+		if !matched {
+			return console.Error("\"%s\" does not exist in commit #%d", filter, commit.Index)
+		}
+	}
+
+	// Write files somewhere other than the working copy
+	if c.IsSet("output") {
+		err = storage.DownloadManyCached(projectConfig, c.String("output"), hashMap)
+		if err != nil {
+			return err
+		}
+
+		console.Success("Restored %d files from commit #%d into \"%s\"", len(hashMap), commit.Index, c.String("output"))
+		return nil
+	}
+
+	// Back up local versions, since they may contain changes that weren't pushed
+	currentCommit, err := vcs.GetCommit(projectConfig, projectConfig.CurrentCommitIndex)
+	if err != nil {
+		return err
+	}
+
+	localPaths := []string{}
+	for path := range hashMap {
+		localPaths = append(localPaths, filepath.Join(projectPath, path))
+	}
+	sort.Strings(localPaths)
+
+	_, err = vcs.BackupFiles(projectConfig, "restore", localPaths, currentCommit.Files)
+	if err != nil {
+		return err
+	}
+
+	err = vcs.ApplyWorkingCopyUpdate(projectConfig, vcs.WorkingCopyUpdate{
+		Operation: "restore",
+		Downloads: hashMap,
+	})
+	if err != nil {
+		return err
+	}
+
+	console.Success("Restored %d files from commit #%d", len(hashMap), commit.Index)
+	return nil
+}
+
+// Write the contents of a file in a commit to stdout
+func Cat(c *cli.Context) error {
+	auth.HasToken()
+
+	// Parse args
+	if c.NArg() != 2 {
+		return console.Error("Please specify a commit and a file path")
+	}
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	projectConfigPath, err := vcs.GetProjectConfigPath()
+	if err != nil {
+		return err
+	}
+
+	commit, err := vcs.ResolveRevision(projectConfig, c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	filters, err := getPathFilters(filepath.Dir(projectConfigPath), c.Args().Tail())
+	if err != nil {
+		return err
+	}
+	path := filters[0]
+
+	fileData, ok := commit.Files[path]
+	if !ok {
+		return console.Error("\"%s\" does not exist in commit #%d", path, commit.Index)
+	}
+
+	// Download file and copy it to stdout
+	tmpDirPath, err := os.MkdirTemp(system.GetTempDir(), "cat-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDirPath)
+
+	// Don't print download progress since it would mix with the file contents
+	err = storage.DownloadManyCached(projectConfig, tmpDirPath, map[string]string{path: fileData.Hash}, storage.WithQuiet())
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDirPath, path))
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(content)
+	return err
+}
@@ -1,65 +1,106 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"strings"
 
-	"github.com/decentvcs/cli/config"
-	"github.com/decentvcs/cli/constants"
 	"github.com/decentvcs/cli/lib/auth"
 	"github.com/decentvcs/cli/lib/console"
-	"github.com/decentvcs/cli/lib/httpvalidation"
 	"github.com/decentvcs/cli/lib/vcs"
 	"github.com/decentvcs/cli/models"
 	"github.com/urfave/cli/v2"
 )
 
-// Reset all local changes and sync to last commit.
+// Create a new commit undoing the changes of a previous commit.
 func Revert(c *cli.Context) error {
 	auth.HasToken()
 
+	// Parse args
+	if c.NArg() != 1 {
+		return console.Error("Please specify the commit to revert")
+	}
+
 	// Get project config
 	projectConfig, err := vcs.GetProjectConfig()
 	if err != nil {
 		return err
 	}
 
-	if projectConfig.CurrentCommitIndex <= 0 {
-		return console.Error("Current commit index is invalid. Please check your project config file.")
+	if err = ensureCleanBranchHead(projectConfig); err != nil {
+		return err
 	}
 
-	// Get current commit by index
-	httpClient := http.Client{}
-	reqUrl := fmt.Sprintf("%s/projects/%s/commits/%d", config.I.VCS.ServerHost, projectConfig.ProjectSlug, projectConfig.CurrentCommitIndex)
-	req, err := http.NewRequest("GET", reqUrl, nil)
+	commit, err := vcs.ResolveRevision(projectConfig, c.Args().First())
 	if err != nil {
 		return err
 	}
-	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
-	res, err := httpClient.Do(req)
+
+	parentCommit, err := vcs.GetParentCommit(projectConfig, commit)
 	if err != nil {
 		return err
 	}
-	if err = httpvalidation.ValidateResponse(res); err != nil {
+	if parentCommit.Index == 0 {
+		return console.Error("Cannot revert the first commit")
+	}
+
+	// Prompt user for confirmation
+	if !c.Bool("yes") {
+		console.Warning("Revert commit #%d (\"%s\") on branch \"%s\"? (y/n)", commit.Index, commit.Message, projectConfig.CurrentBranchName)
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			console.Info("Aborted")
+			return nil
+		}
+	}
+
+	// Apply the inverse of the commit's changes
+	paths := append(append(append([]string{}, commit.CreatedFiles...), commit.ModifiedFiles...), commit.DeletedFiles...)
+	res, err := vcs.ApplyFileChanges(projectConfig, "revert", fmt.Sprintf("revert #%d", commit.Index), commit.Files, parentCommit.Files, paths)
+	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
 
-	// Parse commit
-	var currentCommit models.Commit
-	err = json.NewDecoder(res.Body).Decode(&currentCommit)
+	if len(res.Conflicts) > 0 {
+		printConflicts(res.Conflicts)
+		console.Warning("Reverted with %d conflicts, since later commits changed the same files. Resolve them, then push.", len(res.Conflicts))
+		return nil
+	}
+
+	if len(res.Applied) == 0 {
+		console.Info("Changes of commit #%d have already been undone", commit.Index)
+		return nil
+	}
+
+	message := fmt.Sprintf("Revert #%d: %s", commit.Index, commit.Message)
+	return Push(c, WithNoConfirm(), WithMessage(message))
+}
+
+// Make sure the working copy is on the latest commit of the current branch and has no local
+// changes, so that changes applied to it can be pushed as a commit of their own.
+func ensureCleanBranchHead(projectConfig models.ProjectConfig) error {
+	branch, err := vcs.GetBranchWithCommit(projectConfig, projectConfig.CurrentBranchName)
 	if err != nil {
-		return console.Error("Failed to parse commit: %s", err)
+		return err
 	}
 
-	// Reset all changes to current commit
-	err = vcs.ResetChanges(!c.Bool("yes"))
+	if branch.Commit.Index != projectConfig.CurrentCommitIndex {
+		return console.Error("Your working copy is not on the latest commit of branch \"%s\". Run `dvcs sync` first.", branch.Name)
+	}
+
+	return ensureNoLocalChanges(branch.Commit.Files)
+}
+
+// Make sure the working copy has no changes compared to the files of its current commit.
+func ensureNoLocalChanges(files map[string]models.FileData) error {
+	fc, err := vcs.DetectFileChanges(files, vcs.WithQuiet())
 	if err != nil {
-		console.ErrorPrint("An error occurred while resetting changes")
 		return err
 	}
 
-	// Sync to last commit
-	return vcs.SyncToCommit(projectConfig, currentCommit.Index-1, !c.Bool("yes"))
+	if len(fc.CreatedFilePaths) > 0 || len(fc.ModifiedFilePaths) > 0 || len(fc.DeletedFilePaths) > 0 {
+		return console.Error("You have local changes. Push or reset them first.")
+	}
+
+	return nil
 }
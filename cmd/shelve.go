@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/TwiN/go-color"
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/storage"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/decentvcs/cli/models"
+	"github.com/urfave/cli/v2"
+)
+
+// Upload local changes and share them as a named shelf.
+func CreateShelf(c *cli.Context) error {
+	auth.HasToken()
+
+	// Parse args
+	name := c.String("name")
+	if name == "" {
+		name = fmt.Sprintf("shelf-%s", time.Now().Format("20060102-150405"))
+	}
+
+	regex := regexp.MustCompile(`^[\w\-\.]+$`)
+	if !regex.MatchString(name) {
+		return console.Error("Invalid shelf name; must be alphanumeric, and can contain dashes or periods")
+	}
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	projectConfigPath, err := vcs.GetProjectConfigPath()
+	if err != nil {
+		return err
+	}
+
+	projectPath := filepath.Dir(projectConfigPath)
+
+	paths, err := getPathFilters(projectPath, c.Args().Slice())
+	if err != nil {
+		return err
+	}
+
+	// Detect local changes
+	currentCommit, err := vcs.GetCommit(projectConfig, projectConfig.CurrentCommitIndex)
+	if err != nil {
+		return err
+	}
+
+	fc, err := vcs.DetectFileChanges(currentCommit.Files, vcs.WithPaths(paths))
+	if err != nil {
+		return err
+	}
+
+	if len(fc.CreatedFilePaths) == 0 && len(fc.ModifiedFilePaths) == 0 && len(fc.DeletedFilePaths) == 0 {
+		console.Info("No changes detected")
+		return nil
+	}
+
+	dto := models.ShelfCreateDTO{
+		Name:          name,
+		Message:       c.String("message"),
+		BranchName:    projectConfig.CurrentBranchName,
+		CommitIndex:   projectConfig.CurrentCommitIndex,
+		CreatedFiles:  fc.CreatedFilePaths,
+		ModifiedFiles: fc.ModifiedFilePaths,
+		DeletedFiles:  fc.DeletedFilePaths,
+		Files:         make(map[string]models.FileData),
+		BaseFiles:     make(map[string]models.FileData),
+	}
+
+	// Upload created and modified files
+	uploadHashMap := make(map[string]string)
+	for _, path := range append(append([]string{}, fc.CreatedFilePaths...), fc.ModifiedFilePaths...) {
+		dto.Files[path] = fc.FileDataMap[path]
+		uploadHashMap[filepath.Join(projectPath, path)] = fc.FileDataMap[path].Hash
+	}
+	for _, path := range append(append([]string{}, fc.ModifiedFilePaths...), fc.DeletedFilePaths...) {
+		dto.BaseFiles[path] = currentCommit.Files[path]
+	}
+
+	if len(uploadHashMap) > 0 {
+		err = storage.UploadMany(projectConfig, uploadHashMap)
+		if err != nil {
+			return err
+		}
+	}
+
+	shelf, err := vcs.CreateShelf(projectConfig, dto)
+	if err != nil {
+		return err
+	}
+
+	console.Success("Created shelf \"%s\". Your local changes were kept.", shelf.Name)
+	return nil
+}
+
+// List shelves in the project.
+func ListShelves(c *cli.Context) error {
+	auth.HasToken()
+
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	shelves, err := vcs.GetShelves(projectConfig)
+	if err != nil {
+		return err
+	}
+
+	if len(shelves) == 0 {
+		console.Info("No shelves")
+		return nil
+	}
+
+	for _, shelf := range shelves {
+		authorName, err := vcs.GetUserName(shelf.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		message := shelf.Message
+		if message == "" {
+			message = "No message"
+		}
+
+		fileCount := len(shelf.CreatedFiles) + len(shelf.ModifiedFiles) + len(shelf.DeletedFiles)
+		fmt.Printf(color.InBold("%s")+" %s "+color.InCyan(color.InBold("[%s; #%d]"))+" %s "+color.InGray("(%s)")+" (%d files)\n", shelf.Name, shelf.CreatedAt.Format(time.RFC1123), shelf.BranchName, shelf.CommitIndex, message, authorName, fileCount)
+	}
+
+	return nil
+}
+
+// Apply a shelf's changes to the working copy.
+func ApplyShelf(c *cli.Context) error {
+	auth.HasToken()
+
+	name := c.Args().First()
+	if name == "" {
+		return console.Error("Shelf name is required")
+	}
+
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	shelf, err := vcs.GetShelf(projectConfig, name)
+	if err != nil {
+		return err
+	}
+
+	paths := append(append(append([]string{}, shelf.CreatedFiles...), shelf.ModifiedFiles...), shelf.DeletedFiles...)
+	res, err := vcs.ApplyFileChanges(projectConfig, "shelve", fmt.Sprintf("shelf %s", shelf.Name), shelf.BaseFiles, shelf.Files, paths)
+	if err != nil {
+		return err
+	}
+
+	if len(res.Conflicts) > 0 {
+		printConflicts(res.Conflicts)
+		console.Warning("Applied shelf \"%s\" with %d conflicts", shelf.Name, len(res.Conflicts))
+		return nil
+	}
+
+	console.Success("Applied shelf \"%s\"", shelf.Name)
+	return nil
+}
+
+// Delete a shelf.
+func DeleteShelf(c *cli.Context) error {
+	auth.HasToken()
+
+	name := c.Args().First()
+	if name == "" {
+		return console.Error("Shelf name is required")
+	}
+
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	// Prompt user for confirmation
+	if !c.Bool("yes") {
+		console.Warning("Delete shelf \"%s\"? (y/n)", name)
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			console.Info("Aborted")
+			return nil
+		}
+	}
+
+	if err = vcs.DeleteShelf(projectConfig, name); err != nil {
+		return err
+	}
+
+	console.Success("Deleted shelf \"%s\"", name)
+	return nil
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/TwiN/go-color"
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/system"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/decentvcs/cli/models"
+	"github.com/urfave/cli/v2"
+)
+
+// Print commit details
+func Show(c *cli.Context) error {
+	auth.HasToken()
+
+	// Parse args
+	if c.NArg() != 1 {
+		return console.Error("Please specify a commit")
+	}
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	commit, err := vcs.ResolveRevision(projectConfig, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	branch, err := vcs.GetBranch(projectConfig, commit.BranchID)
+	if err != nil {
+		return err
+	}
+
+	authorName, err := vcs.GetUserName(commit.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	// Previous versions of modified and deleted files come from the parent commit
+	parentCommit := models.Commit{}
+	hasParent := false
+	if len(commit.ModifiedFiles) > 0 || len(commit.DeletedFiles) > 0 {
+		parentCommit, err = vcs.GetParentCommit(projectConfig, commit)
+		if err != nil {
+			console.Verbose("%v", err)
+			console.Warning("Could not determine the parent of commit #%d, so modified files are shown as created", commit.Index)
+		} else {
+			hasParent = parentCommit.Index != 0
+		}
+	}
+
+	// Print header
+	fmt.Println(color.InCyan(color.InBold(fmt.Sprintf("Commit #%d", commit.Index))))
+	fmt.Printf("Branch: %s\n", branch.Name)
+	fmt.Printf("Author: %s\n", authorName)
+	fmt.Printf("Date:   %s\n", commit.CreatedAt.Format(time.RFC1123))
+	fmt.Printf("\n    %s\n\n", commit.Message)
+
+	// Print changed files
+	diffs := []fileDiff{}
+	for _, path := range commit.CreatedFiles {
+		diffs = append(diffs, fileDiff{
+			Path:    path,
+			Status:  "A",
+			NewHash: commit.Files[path].Hash,
+			NewSize: commit.Files[path].Size,
+		})
+	}
+	for _, path := range commit.ModifiedFiles {
+		if !hasParent {
+			diffs = append(diffs, fileDiff{
+				Path:    path,
+				Status:  "A",
+				NewHash: commit.Files[path].Hash,
+				NewSize: commit.Files[path].Size,
+			})
+			continue
+		}
+
+		diffs = append(diffs, fileDiff{
+			Path:    path,
+			Status:  "M",
+			OldHash: parentCommit.Files[path].Hash,
+			NewHash: commit.Files[path].Hash,
+			OldSize: parentCommit.Files[path].Size,
+			NewSize: commit.Files[path].Size,
+		})
+	}
+	for _, path := range commit.DeletedFiles {
+		diffs = append(diffs, fileDiff{
+			Path:    path,
+			Status:  "D",
+			OldHash: parentCommit.Files[path].Hash,
+			OldSize: parentCommit.Files[path].Size,
+		})
+	}
+
+	diffs = filterFileDiffs(diffs, nil)
+	if len(diffs) == 0 {
+		console.Info("No files changed")
+		return nil
+	}
+
+	printDiffSizes(diffs)
+
+	if !c.Bool("patch") {
+		return nil
+	}
+
+	// Print text diffs against parent commit
+	tmpDirPath, err := os.MkdirTemp(system.GetTempDir(), "show-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDirPath)
+
+	err = downloadFileDiffs(projectConfig, tmpDirPath, diffs)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	return printFileDiffs(diffs)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/TwiN/go-color"
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/decentvcs/cli/models"
+	"github.com/urfave/cli/v2"
+)
+
+// Save local changes into a stash and reset them.
+func StashPush(c *cli.Context) error {
+	auth.HasToken()
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	projectConfigPath, err := vcs.GetProjectConfigPath()
+	if err != nil {
+		return err
+	}
+
+	paths, err := getPathFilters(filepath.Dir(projectConfigPath), c.Args().Slice())
+	if err != nil {
+		return err
+	}
+
+	stash, err := vcs.StashChanges(projectConfig, c.String("message"), paths)
+	if err != nil {
+		return err
+	}
+
+	fileCount := len(stash.CreatedFiles) + len(stash.ModifiedFiles) + len(stash.DeletedFiles)
+	console.Success("Stashed changes to %d files", fileCount)
+	return nil
+}
+
+// List stashes.
+func ListStashes(c *cli.Context) error {
+	stashes, err := vcs.ListStashes()
+	if err != nil {
+		return err
+	}
+
+	if len(stashes) == 0 {
+		console.Info("No stashes")
+		return nil
+	}
+
+	for i, stash := range stashes {
+		message := stash.Message
+		if message == "" {
+			message = "No message"
+		}
+
+		fileCount := len(stash.CreatedFiles) + len(stash.ModifiedFiles) + len(stash.DeletedFiles)
+		fmt.Printf(color.InBold("%d")+" %s "+color.InCyan(color.InBold("[%s; #%d]"))+" %s (%d files)\n", i, stash.CreatedAt.Format(time.RFC1123), stash.BranchName, stash.CommitIndex, message, fileCount)
+	}
+
+	return nil
+}
+
+// Reapply a stash, keeping it.
+func StashApply(c *cli.Context) error {
+	_, err := applyStash(c)
+	return err
+}
+
+// Reapply a stash and delete it if there were no conflicts.
+func StashPop(c *cli.Context) error {
+	stash, err := applyStash(c)
+	if err != nil || stash == nil {
+		return err
+	}
+
+	if err = vcs.DropStash(*stash); err != nil {
+		return err
+	}
+
+	console.Info("Dropped stash %s", stash.ID)
+	return nil
+}
+
+// Delete a stash.
+func StashDrop(c *cli.Context) error {
+	stash, err := getStashFromArgs(c)
+	if err != nil {
+		return err
+	}
+
+	if err = vcs.DropStash(stash); err != nil {
+		return err
+	}
+
+	console.Success("Dropped stash %s", stash.ID)
+	return nil
+}
+
+// Reapply the stash specified in args.
+// Returns the stash if it was applied without conflicts.
+func applyStash(c *cli.Context) (*models.Stash, error) {
+	auth.HasToken()
+
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	stash, err := getStashFromArgs(c)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := vcs.ApplyStash(projectConfig, stash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Conflicts) > 0 {
+		printConflicts(res.Conflicts)
+		console.Warning("Applied stash with %d conflicts. The stash was kept in case you need it.", len(res.Conflicts))
+		return nil, nil
+	}
+
+	console.Success("Applied stash %s", stash.ID)
+	return &stash, nil
+}
+
+// Get the stash specified by its index in args, defaulting to the newest one.
+func getStashFromArgs(c *cli.Context) (models.Stash, error) {
+	index := 0
+	if c.NArg() > 0 {
+		var err error
+		index, err = strconv.Atoi(c.Args().First())
+		if err != nil {
+			return models.Stash{}, console.Error("Invalid stash index \"%s\"", c.Args().First())
+		}
+	}
+
+	return vcs.GetStash(index)
+}
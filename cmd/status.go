@@ -98,7 +98,11 @@ func PrintStatus(c *cli.Context) error {
 
 	fmt.Printf(color.Ize(color.Cyan, "Project: ")+"%s (%s)\n", project.Name, project.ID)
 	fmt.Printf(color.Ize(color.Cyan, "Branch:  ")+"%s (%s)\n", branch.Name, branch.ID)
-	fmt.Printf(color.Ize(color.Cyan, "Commit:  ")+"#%d (%s)\n", commit.Index, commit.ID)
+	if projectConfig.Detached {
+		fmt.Printf(color.Ize(color.Cyan, "Commit:  ")+"#%d (%s) "+color.InYellow("(detached)")+"\n", commit.Index, commit.ID)
+	} else {
+		fmt.Printf(color.Ize(color.Cyan, "Commit:  ")+"#%d (%s)\n", commit.Index, commit.ID)
+	}
 
 	return nil
 }
@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"strconv"
-
 	"github.com/decentvcs/cli/lib/auth"
 	"github.com/decentvcs/cli/lib/console"
 	"github.com/decentvcs/cli/lib/vcs"
@@ -23,6 +21,16 @@ func Sync(c *cli.Context) error {
 		return console.Error("Current commit index is invalid. Please check your project config file.")
 	}
 
-	commitIndex, _ := strconv.Atoi(c.Args().Get(0))
-	return vcs.SyncToCommit(projectConfig, commitIndex, !c.Bool("yes"))
+	// Sync to latest commit on current branch unless a revision is specified
+	commitIndex := 0
+	if c.NArg() > 0 {
+		commit, err := vcs.ResolveRevision(projectConfig, c.Args().First())
+		if err != nil {
+			return err
+		}
+		commitIndex = commit.Index
+	}
+
+	_, err = vcs.SyncToCommit(projectConfig, commitIndex, !c.Bool("yes"))
+	return err
 }
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TwiN/go-color"
+	"github.com/decentvcs/cli/lib/auth"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/vcs"
+	"github.com/decentvcs/cli/models"
+	"github.com/urfave/cli/v2"
+)
+
+// Create a tag pointing at a commit.
+func CreateTag(c *cli.Context) error {
+	auth.HasToken()
+
+	// Parse args
+	name := c.Args().First()
+	if name == "" {
+		return console.Error("Tag name is required")
+	}
+
+	regex := regexp.MustCompile(`^[\w\-\.]+$`)
+	if !regex.MatchString(name) {
+		return console.Error("Invalid tag name; must be alphanumeric, and can contain dashes or periods")
+	}
+
+	// Names that would be resolved as another kind of revision
+	if _, err := strconv.Atoi(name); err == nil {
+		return console.Error("Invalid tag name; cannot be a number since it would be mistaken for a commit index")
+	}
+	if strings.EqualFold(name, "head") {
+		return console.Error("Invalid tag name; \"%s\" is reserved for the current commit", name)
+	}
+
+	revision := c.Args().Get(1)
+	if revision == "" {
+		revision = "head"
+	}
+
+	// Get project config
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	branches, err := vcs.GetBranches(projectConfig)
+	if err != nil {
+		return err
+	}
+
+	for _, branch := range branches {
+		if branch.Name == name {
+			return console.Error("A branch named \"%s\" already exists; tags and branches cannot share names", name)
+		}
+	}
+
+	commit, err := vcs.ResolveRevision(projectConfig, revision)
+	if err != nil {
+		return err
+	}
+
+	tag, err := vcs.CreateTag(projectConfig, models.TagCreateDTO{
+		Name:        name,
+		Message:     c.String("message"),
+		CommitIndex: commit.Index,
+	})
+	if err != nil {
+		return err
+	}
+
+	console.Success("Created tag \"%s\" pointing at commit #%d", tag.Name, tag.CommitIndex)
+	return nil
+}
+
+// List tags in the project.
+func ListTags(c *cli.Context) error {
+	auth.HasToken()
+
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	tags, err := vcs.GetTags(projectConfig)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		console.Info("No tags")
+		return nil
+	}
+
+	for _, tag := range tags {
+		authorName, err := vcs.GetUserName(tag.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		message := tag.Message
+		if message == "" {
+			message = "No message"
+		}
+
+		fmt.Printf(color.InBold("%s")+" "+color.InCyan(color.InBold("#%d"))+" %s %s "+color.InGray("(%s)")+"\n", tag.Name, tag.CommitIndex, tag.CreatedAt.Format(time.RFC1123), message, authorName)
+	}
+
+	return nil
+}
+
+// Make sure no tag has the specified name, since tags take precedence over branches when resolving
+// revisions.
+func ensureNoTagNamed(projectConfig models.ProjectConfig, name string) error {
+	_, ok, err := vcs.FindTag(projectConfig, name)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		return console.Error("A tag named \"%s\" already exists; tags and branches cannot share names", name)
+	}
+
+	return nil
+}
+
+// Delete a tag. The commit it points at is not affected.
+func DeleteTag(c *cli.Context) error {
+	auth.HasToken()
+
+	name := c.Args().First()
+	if name == "" {
+		return console.Error("Tag name is required")
+	}
+
+	projectConfig, err := vcs.GetProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	// Prompt user for confirmation
+	if !c.Bool("yes") {
+		console.Warning("Delete tag \"%s\"? (y/n)", name)
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			console.Info("Aborted")
+			return nil
+		}
+	}
+
+	if err = vcs.DeleteTag(projectConfig, name); err != nil {
+		return err
+	}
+
+	console.Success("Deleted tag \"%s\"", name)
+	return nil
+}
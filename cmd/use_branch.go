@@ -60,6 +60,7 @@ func UseBranch(c *cli.Context) error {
 
 	// Set the current branch in project config
 	projectConfig.CurrentBranchName = branch.Name
+	projectConfig.Detached = false
 	projectConfigPath, err := vcs.GetProjectConfigPath()
 	if err != nil {
 		return err
@@ -80,7 +81,7 @@ func UseBranch(c *cli.Context) error {
 
 	// Sync
 	if projectConfig.CurrentCommitIndex != branch.Commit.Index {
-		err = vcs.SyncToCommit(projectConfig, branch.Commit.Index, true)
+		_, err = vcs.SyncToCommit(projectConfig, branch.Commit.Index, true)
 		if err != nil {
 			return err
 		}
@@ -37,6 +37,18 @@ type VCSStorageConfig struct {
 	MaxUploadAttempts int `yaml:"max_upload_attempts"`
 	// Time in seconds to wait until retrying an upload.
 	RateLimitRetryDelay int `yaml:"rate_limit_retry_delay"`
+	// Max size in MB of the local cache of downloaded objects. Least recently used objects are
+	// deleted first.
+	CacheMaxSizeMB int64 `yaml:"cache_max_size_mb"`
+	// Min age in days of unused objects before `dvcs purge` deletes them.
+	PurgeRetentionDays int `yaml:"purge_retention_days"`
+}
+
+type VCSRecoveryConfig struct {
+	// Max amount of recovery snapshots kept per project. Oldest snapshots are deleted first.
+	MaxSnapshots int `yaml:"max_snapshots"`
+	// Max age in days of recovery snapshots before they're deleted.
+	RetentionDays int `yaml:"retention_days"`
 }
 
 type VCSConfig struct {
@@ -46,6 +58,8 @@ type VCSConfig struct {
 	MaxFileSizeForDiff int64 `yaml:"max_file_size_for_diff"`
 	// Storage configuration.
 	Storage VCSStorageConfig
+	// Configuration for backups of local files replaced by sync, reset, and merge.
+	Recovery VCSRecoveryConfig
 }
 
 type AuthConfig struct {
@@ -124,9 +138,15 @@ func InitConfig() Config {
 			VCS: VCSConfig{
 				MaxFileSizeForDiff: 1 * 1024 * 1024, // 1 MB
 				Storage: VCSStorageConfig{
-					PartSize:         64 * 1024 * 1024, // 64 MB
-					UploadPoolSize:   32,
-					DownloadPoolSize: 32,
+					PartSize:           64 * 1024 * 1024, // 64 MB
+					UploadPoolSize:     32,
+					DownloadPoolSize:   32,
+					CacheMaxSizeMB:     2048,
+					PurgeRetentionDays: 14,
+				},
+				Recovery: VCSRecoveryConfig{
+					MaxSnapshots:  20,
+					RetentionDays: 14,
 				},
 			},
 		}
@@ -199,6 +219,18 @@ func SetInternalConfigFields(config *Config) {
 	if config.Env == "" {
 		config.Env = EnvPrd
 	}
+	if config.VCS.Storage.CacheMaxSizeMB == 0 {
+		config.VCS.Storage.CacheMaxSizeMB = 2048
+	}
+	if config.VCS.Storage.PurgeRetentionDays == 0 {
+		config.VCS.Storage.PurgeRetentionDays = 14
+	}
+	if config.VCS.Recovery.MaxSnapshots == 0 {
+		config.VCS.Recovery.MaxSnapshots = 20
+	}
+	if config.VCS.Recovery.RetentionDays == 0 {
+		config.VCS.Recovery.RetentionDays = 14
+	}
 
 	// Set internal config fields
 	config.WebsiteURL = getDashURL(config.Env)
@@ -31,6 +31,15 @@ const ScopeTeamUpdateUsage = "team:update_usage"
 // File system
 const ProjectFileName = ".decent"
 const IgnoreFileName = ".decentignore"
+const CommitConfigFileName = ".decentcommit.yml"
+const ProjectDataDirName = ".decentdata"
+const RecoveryDirName = "recovery"
+const StagingDirName = "staging"
+const JournalFileName = "journal.json"
+const BisectStateFileName = "bisect.json"
+const StashDirName = "stash"
+const ChangelistsFileName = "changelists.json"
+const CommitMessageFileName = "COMMIT_MESSAGE"
 
 // Error messages
 const ErrNoProject = "Looks like you're not in a DecentVCS project. You can use `dvcs init` to create one."
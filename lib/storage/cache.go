@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/decentvcs/cli/config"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/system"
+	"github.com/decentvcs/cli/models"
+)
+
+// Download many objects from storage to local file system, reusing objects from the local object
+// cache when possible. Newly downloaded objects are added to the cache.
+//
+// Params:
+//
+// - projectConfig: Project config
+//
+// - dest: Local path where downloaded files are written to. Can be relative or absolute.
+//
+// - hashMap: Map of local file paths to file hashes
+func DownloadManyCached(projectConfig models.ProjectConfig, dest string, hashMap map[string]string, opts ...func(*DownloadOptions)) error {
+	// Build options
+	o := &DownloadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	cacheDir := system.GetCacheDir()
+
+	// Copy cached objects
+	missingHashMap := make(map[string]string)
+	for path, hash := range hashMap {
+		cachePath := filepath.Join(cacheDir, hash)
+		if _, err := os.Stat(cachePath); err != nil {
+			missingHashMap[path] = hash
+			continue
+		}
+
+		if !o.Quiet {
+			console.Verbose("[%s] Using cached object for \"%s\"", hash, path)
+		}
+		if err := system.CopyFile(cachePath, filepath.Join(dest, path)); err != nil {
+			return err
+		}
+
+		// Mark as recently used
+		now := time.Now()
+		os.Chtimes(cachePath, now, now)
+	}
+
+	if len(missingHashMap) == 0 {
+		return nil
+	}
+
+	// Download the rest, once per unique object
+	uniqueHashMap := make(map[string]string)
+	hashPaths := make(map[string]string)
+	for path, hash := range missingHashMap {
+		if _, ok := hashPaths[hash]; !ok {
+			hashPaths[hash] = path
+			uniqueHashMap[path] = hash
+		}
+	}
+
+	if err := DownloadMany(projectConfig, dest, uniqueHashMap, opts...); err != nil {
+		return err
+	}
+
+	// Add downloaded objects to cache
+	for hash, path := range hashPaths {
+		if err := system.CopyFile(filepath.Join(dest, path), filepath.Join(cacheDir, hash)); err != nil && !o.Quiet {
+			console.Warning("Failed to cache object for \"%s\": %v", path, err)
+		}
+	}
+
+	// Copy objects shared by multiple paths
+	for path, hash := range missingHashMap {
+		if _, ok := uniqueHashMap[path]; ok {
+			continue
+		}
+
+		if err := system.CopyFile(filepath.Join(dest, hashPaths[hash]), filepath.Join(dest, path)); err != nil {
+			return err
+		}
+	}
+
+	if err := pruneCache(cacheDir); err != nil && !o.Quiet {
+		console.Warning("Failed to prune object cache: %v", err)
+	}
+
+	return nil
+}
+
+// Delete least recently used objects from the cache until it fits within the configured max size.
+func pruneCache(cacheDir string) error {
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		return err
+	}
+
+	infos := []os.FileInfo{}
+	var totalSize int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		infos = append(infos, info)
+		totalSize += info.Size()
+	}
+
+	maxSize := config.I.VCS.Storage.CacheMaxSizeMB * 1024 * 1024
+	if totalSize <= maxSize {
+		return nil
+	}
+
+	// Oldest first
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().Before(infos[j].ModTime())
+	})
+
+	for _, info := range infos {
+		if totalSize <= maxSize {
+			break
+		}
+
+		console.Verbose("Deleting cached object %s...", info.Name())
+		if err := os.Remove(filepath.Join(cacheDir, info.Name())); err != nil {
+			return err
+		}
+		totalSize -= info.Size()
+	}
+
+	return nil
+}
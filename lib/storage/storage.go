@@ -473,6 +473,18 @@ func uploadPart(ctx context.Context, params UploadPartParams) {
 	}
 }
 
+type DownloadOptions struct {
+	// If true, progress and warnings aren't printed.
+	Quiet bool
+}
+
+// Don't print progress, e.g. when the downloaded files are written to stdout.
+func WithQuiet() func(*DownloadOptions) {
+	return func(o *DownloadOptions) {
+		o.Quiet = true
+	}
+}
+
 // Download many objects from storage to local file system.
 //
 // Params:
@@ -484,13 +496,21 @@ func uploadPart(ctx context.Context, params UploadPartParams) {
 // - hashMap: Map of local file paths to file hashes
 //
 // Returns map of object keys to data.
-func DownloadMany(projectConfig models.ProjectConfig, dest string, hashMap map[string]string) error {
+func DownloadMany(projectConfig models.ProjectConfig, dest string, hashMap map[string]string, opts ...func(*DownloadOptions)) error {
 	auth.HasToken()
 
+	// Build options
+	o := &DownloadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	console.Info("Getting things ready...")
+	if !o.Quiet {
+		console.Info("Getting things ready...")
+	}
 	startTime := time.Now()
 
 	teamName := strings.Split(projectConfig.ProjectSlug, "/")[0]
@@ -544,6 +564,9 @@ func DownloadMany(projectConfig models.ProjectConfig, dest string, hashMap map[s
 	// Download objects in parallel (limited to pool size)
 	pool := workerpool.New(config.I.VCS.Storage.DownloadPoolSize)
 	bar := progressbar.Default(int64(len(hashMap)))
+	if o.Quiet {
+		bar = progressbar.DefaultSilent(int64(len(hashMap)))
+	}
 	for key, r := range presignRes {
 		// NOTE: ARGUMENTS MUST BE OUTSIDE OF SUBMITTED FUNCTION
 		path := util.ReverseLookup(hashMap, key)
@@ -557,6 +580,7 @@ func DownloadMany(projectConfig models.ProjectConfig, dest string, hashMap map[s
 			FilePath:      path,
 			URL:           r.URLs[0],
 			Bar:           bar,
+			Quiet:         o.Quiet,
 		}
 		pool.Submit(func() {
 			download(ctx, params)
@@ -567,7 +591,9 @@ func DownloadMany(projectConfig models.ProjectConfig, dest string, hashMap map[s
 	pool.StopWait()
 
 	endTime := time.Now()
-	console.Info("Downloaded %d files in %s", len(hashMap), endTime.Sub(startTime))
+	if !o.Quiet {
+		console.Info("Downloaded %d files in %s", len(hashMap), endTime.Sub(startTime))
+	}
 
 	// Delete access key
 	// NOTE: Error is ignored on purpose since alerting the user could be a security risk
@@ -583,6 +609,7 @@ type DownloadParams struct {
 	FilePath      string
 	URL           string
 	Bar           *progressbar.ProgressBar
+	Quiet         bool
 }
 
 // Download object from storage to local file system.
@@ -659,7 +686,9 @@ func download(ctx context.Context, params DownloadParams) {
 
 	// Check if zstd compressed
 	if header := hex.EncodeToString(dData[:4]); header == constants.ZstdHeader {
-		console.Verbose("Decompressing file \"%s\"...", path)
+		if !params.Quiet {
+			console.Verbose("Decompressing file \"%s\"...", path)
+		}
 		// File is compressed via zstd, decompress it
 		//
 		// Rename compressed file to .zst extension
@@ -688,6 +717,8 @@ func download(ctx context.Context, params DownloadParams) {
 			panic(console.Error("Failed to delete compressed file \"%s\": %v", path, err))
 		}
 
-		console.Verbose("File \"%s\" decompressed successfully", path)
+		if !params.Quiet {
+			console.Verbose("File \"%s\" decompressed successfully", path)
+		}
 	}
 }
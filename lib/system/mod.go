@@ -3,11 +3,13 @@ package system
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 )
 
 // Open the default browser with the given URL.
@@ -55,6 +57,27 @@ func GetTempDir() string {
 	return tempDir
 }
 
+// Get cache directory specific to Decent.
+// The directory is created if it doesn't exist.
+func GetCacheDir() string {
+	// Get user home dir
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Create cache dir if it doesn't exist
+	cacheDir := filepath.Join(homeDir, "decent", "cache")
+	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+		err = os.MkdirAll(cacheDir, 0755)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	return cacheDir
+}
+
 // Returns a slice of all files in a directory recursively.
 func ListFiles(dir string) ([]string, error) {
 	var res []string
@@ -102,3 +125,83 @@ func FindFileUpwards(filename string) (string, error) {
 
 	return foundPath, nil
 }
+
+// Copy a file, creating the destination directory recursively if needed.
+func CopyFile(src string, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return err
+	}
+
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, srcInfo.Mode())
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	_, err = io.Copy(dstFile, srcFile)
+	return err
+}
+
+// Write a file atomically by writing to a temp file in the same directory and renaming it.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+
+	_, err = tempFile.Write(data)
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, perm)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	return os.Rename(tempPath, path)
+}
+
+// Returns true if stdin is an interactive terminal.
+func IsTerminal() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// Get the command for the user's preferred text editor from the environment, with a platform
+// default as fallback.
+func GetEditorCommand() []string {
+	for _, envVar := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(envVar)); len(editor) > 0 {
+			return editor
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+
+	return []string{"vi"}
+}
@@ -2,7 +2,9 @@ package util
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/decentvcs/cli/lib/console"
 	"golang.org/x/exp/maps"
 )
 
@@ -77,3 +79,24 @@ func MergeMaps[K comparable, V any](m ...map[K]V) map[K]V {
 
 	return result
 }
+
+// Parse a date, either as "YYYY-MM-DD" (local time) or RFC 3339.
+//
+// @param endOfDay - If true, dates without a time refer to the last moment of the day instead of
+// the first.
+func ParseDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, console.Error("Invalid date \"%s\", expected YYYY-MM-DD or RFC 3339", value)
+	}
+
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,254 @@
+package vcs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/storage"
+	"github.com/decentvcs/cli/lib/system"
+	"github.com/decentvcs/cli/lib/util"
+	"github.com/decentvcs/cli/models"
+)
+
+// File that couldn't be updated cleanly and must be resolved manually.
+type Conflict struct {
+	Path   string
+	Reason string
+}
+
+// Result of `vcs.ApplyFileChanges()`
+type ApplyFileChangesResult struct {
+	// Relative fs paths of files written to or deleted from the working copy.
+	Applied []string
+	// Files with conflicting changes. Text files contain conflict markers, other files are left
+	// untouched.
+	Conflicts []Conflict
+}
+
+type ApplyFileChangesOptions struct {
+	// Map of relative fs paths to local files holding their target versions, used instead of
+	// downloading them.
+	TargetFiles map[string]string
+}
+
+// Use local files as the target versions of some paths instead of downloading them.
+func WithTargetFiles(files map[string]string) func(*ApplyFileChangesOptions) {
+	return func(o *ApplyFileChangesOptions) {
+		o.TargetFiles = files
+	}
+}
+
+// Apply the changes between two versions of some files onto the working copy.
+//
+// Each path is three-way merged, using its version in `base` as the common ancestor, its version in
+// `target` as the change to apply, and the local file as the version to apply it to. Files that
+// weren't changed locally are replaced or deleted outright. Text files changed on both sides are
+// merged line by line, while other files changed on both sides are reported as conflicts.
+//
+// Local files are backed up before being replaced.
+//
+// @param operation - Name of the operation, used for backups (e.g. "revert")
+//
+// @param label - Label for the target side in conflict markers (e.g. "revert #42")
+//
+// @param paths - Relative fs paths of the files to apply changes for
+func ApplyFileChanges(projectConfig models.ProjectConfig, operation string, label string, base map[string]models.FileData, target map[string]models.FileData, paths []string, opts ...func(*ApplyFileChangesOptions)) (ApplyFileChangesResult, error) {
+	// Build options
+	o := &ApplyFileChangesOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	projectConfigPath, err := GetProjectConfigPath()
+	if err != nil {
+		return ApplyFileChangesResult{}, err
+	}
+
+	projectPath := filepath.Dir(projectConfigPath)
+
+	res := ApplyFileChangesResult{
+		Applied:   []string{},
+		Conflicts: []Conflict{},
+	}
+
+	// Determine what to do with each file
+	replaceHashMap := make(map[string]string)
+	mergeHashMap := make(map[string]string)
+	deletes := []string{}
+	for _, path := range paths {
+		baseFile := base[path]
+		targetFile, inTarget := target[path]
+
+		localHash := ""
+		localPath := filepath.Join(projectPath, path)
+		if _, err := os.Stat(localPath); err == nil {
+			localHash, err = GetFileHash(localPath)
+			if err != nil {
+				return ApplyFileChangesResult{}, err
+			}
+		}
+
+		switch {
+		case localHash == targetFile.Hash:
+			// Already up to date
+		case localHash == baseFile.Hash:
+			// Unchanged locally
+			if inTarget {
+				replaceHashMap[path] = targetFile.Hash
+			} else {
+				deletes = append(deletes, path)
+			}
+		case !inTarget:
+			res.Conflicts = append(res.Conflicts, Conflict{Path: path, Reason: fmt.Sprintf("modified locally, deleted in %s", label)})
+		case localHash == "":
+			// Restore their version so the user can decide whether to keep it
+			res.Conflicts = append(res.Conflicts, Conflict{Path: path, Reason: fmt.Sprintf("deleted locally, modified in %s", label)})
+			replaceHashMap[path] = targetFile.Hash
+		default:
+			mergeable, err := IsMergeable(localPath)
+			if err != nil {
+				return ApplyFileChangesResult{}, err
+			}
+
+			if !mergeable {
+				res.Conflicts = append(res.Conflicts, Conflict{Path: path, Reason: fmt.Sprintf("binary file modified locally and in %s", label)})
+				continue
+			}
+
+			mergeHashMap[path] = targetFile.Hash
+		}
+	}
+
+	if len(replaceHashMap) == 0 && len(mergeHashMap) == 0 && len(deletes) == 0 {
+		return res, nil
+	}
+
+	tmpDirPath, err := os.MkdirTemp(system.GetTempDir(), operation+"-")
+	if err != nil {
+		return ApplyFileChangesResult{}, err
+	}
+	defer os.RemoveAll(tmpDirPath)
+
+	// Download target versions, and base versions of files to merge
+	targetDirPath := filepath.Join(tmpDirPath, "target")
+	baseDirPath := filepath.Join(tmpDirPath, "base")
+	targetHashMap := make(map[string]string)
+	baseHashMap := make(map[string]string)
+	for path, hash := range replaceHashMap {
+		if _, ok := o.TargetFiles[path]; !ok {
+			targetHashMap[path] = hash
+		}
+	}
+	for path, hash := range mergeHashMap {
+		if _, ok := o.TargetFiles[path]; !ok {
+			targetHashMap[path] = hash
+		}
+		if baseFile, ok := base[path]; ok {
+			baseHashMap[path] = baseFile.Hash
+		}
+	}
+
+	for dirPath, hashMap := range map[string]map[string]string{targetDirPath: targetHashMap, baseDirPath: baseHashMap} {
+		if len(hashMap) == 0 {
+			continue
+		}
+
+		if err = storage.DownloadManyCached(projectConfig, dirPath, hashMap); err != nil {
+			return ApplyFileChangesResult{}, err
+		}
+	}
+
+	// Get local paths of target versions
+	targetPaths := make(map[string]string)
+	for path := range util.MergeMaps(replaceHashMap, mergeHashMap) {
+		if targetPath, ok := o.TargetFiles[path]; ok {
+			targetPaths[path] = targetPath
+		} else {
+			targetPaths[path] = filepath.Join(targetDirPath, path)
+		}
+	}
+
+	files := make(map[string]string)
+	for path := range replaceHashMap {
+		files[path] = targetPaths[path]
+	}
+
+	// Merge into copies of the local files
+	emptyFilePath := filepath.Join(tmpDirPath, "empty")
+	if err = os.WriteFile(emptyFilePath, []byte{}, 0644); err != nil {
+		return ApplyFileChangesResult{}, err
+	}
+
+	console.Verbose("Merging %d files...", len(mergeHashMap))
+	for path := range mergeHashMap {
+		targetPath := targetPaths[path]
+		mergeable, err := IsMergeable(targetPath)
+		if err != nil {
+			return ApplyFileChangesResult{}, err
+		}
+		if !mergeable {
+			res.Conflicts = append(res.Conflicts, Conflict{Path: path, Reason: fmt.Sprintf("modified locally, replaced with a binary file in %s", label)})
+			continue
+		}
+
+		basePath := emptyFilePath
+		if _, ok := baseHashMap[path]; ok {
+			basePath = filepath.Join(baseDirPath, path)
+		}
+
+		mergedPath := filepath.Join(tmpDirPath, "merged", path)
+		if err = system.CopyFile(filepath.Join(projectPath, path), mergedPath); err != nil {
+			return ApplyFileChangesResult{}, err
+		}
+
+		conflicted, err := MergeTextFile(mergedPath, basePath, targetPath, label)
+		if err != nil {
+			return ApplyFileChangesResult{}, err
+		}
+		if conflicted {
+			res.Conflicts = append(res.Conflicts, Conflict{Path: path, Reason: fmt.Sprintf("modified locally and in %s", label)})
+		}
+
+		files[path] = mergedPath
+	}
+
+	// Back up local files before replacing them
+	changedPaths := append([]string{}, deletes...)
+	for path := range files {
+		changedPaths = append(changedPaths, path)
+	}
+	sort.Strings(changedPaths)
+
+	currentCommit, err := GetCommit(projectConfig, projectConfig.CurrentCommitIndex)
+	if err != nil {
+		return ApplyFileChangesResult{}, err
+	}
+
+	backupPaths := []string{}
+	for _, path := range changedPaths {
+		backupPaths = append(backupPaths, filepath.Join(projectPath, path))
+	}
+
+	if _, err = BackupFiles(projectConfig, operation, backupPaths, currentCommit.Files); err != nil {
+		return ApplyFileChangesResult{}, err
+	}
+
+	err = ApplyWorkingCopyUpdate(projectConfig, WorkingCopyUpdate{
+		Operation: operation,
+		Files:     files,
+		Deletes:   deletes,
+	})
+	if err != nil {
+		return ApplyFileChangesResult{}, err
+	}
+
+	res.Applied = changedPaths
+	sort.Slice(res.Conflicts, func(i, j int) bool {
+		return res.Conflicts[i].Path < res.Conflicts[j].Path
+	})
+
+	return res, nil
+}
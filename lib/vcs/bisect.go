@@ -0,0 +1,75 @@
+package vcs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/system"
+	"github.com/decentvcs/cli/models"
+)
+
+// Get the state of the ongoing bisect.
+// Returns nil if no bisect is in progress.
+func GetBisectState() (*models.BisectState, error) {
+	statePath, err := getBisectStatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	stateBytes, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state models.BisectState
+	if err = json.Unmarshal(stateBytes, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// Save the state of the ongoing bisect.
+func SaveBisectState(state models.BisectState) error {
+	statePath, err := getBisectStatePath()
+	if err != nil {
+		return err
+	}
+
+	stateJson, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return system.WriteFileAtomic(statePath, stateJson, 0644)
+}
+
+// Delete the state of the ongoing bisect, if any.
+func DeleteBisectState() error {
+	statePath, err := getBisectStatePath()
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(statePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Returns the path to the bisect state file.
+func getBisectStatePath() (string, error) {
+	dataDirPath, err := GetProjectDataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDirPath, constants.BisectStateFileName), nil
+}
@@ -0,0 +1,552 @@
+package vcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/decentvcs/cli/config"
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/httpvalidation"
+	"github.com/decentvcs/cli/models"
+	"github.com/samber/lo"
+)
+
+// Get a branch by name or ID.
+func GetBranch(projectConfig models.ProjectConfig, branchNameOrID string) (models.Branch, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/branches/%s", config.I.VCS.ServerHost, projectConfig.ProjectSlug, branchNameOrID)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return models.Branch{}, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return models.Branch{}, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return models.Branch{}, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var branch models.Branch
+	err = json.NewDecoder(res.Body).Decode(&branch)
+	if err != nil {
+		return models.Branch{}, console.Error("Failed to parse branch: %v", err)
+	}
+
+	return branch, nil
+}
+
+// Create a new branch pointing at the specified commit, which the server records as the branch's
+// fork point.
+func CreateBranch(projectConfig models.ProjectConfig, name string, commitIndex int) (models.Branch, error) {
+	bodyJson, err := json.Marshal(models.BranchCreateDTO{
+		Name:        name,
+		CommitIndex: commitIndex,
+	})
+	if err != nil {
+		return models.Branch{}, err
+	}
+
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/branches", config.I.VCS.ServerHost, projectConfig.ProjectSlug)
+	req, err := http.NewRequest("POST", reqUrl, bytes.NewBuffer(bodyJson))
+	if err != nil {
+		return models.Branch{}, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return models.Branch{}, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return models.Branch{}, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var branch models.Branch
+	err = json.NewDecoder(res.Body).Decode(&branch)
+	if err != nil {
+		return models.Branch{}, console.Error("Failed to parse branch: %v", err)
+	}
+
+	return branch, nil
+}
+
+// Get all commits made on the specified branch, sorted by index in ascending order.
+//
+// Only commits created on the branch itself are returned, not the ones it was created from.
+func GetBranchCommits(projectConfig models.ProjectConfig, branchNameOrID string) ([]models.Commit, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/branches/%s/commits", config.I.VCS.ServerHost, projectConfig.ProjectSlug, branchNameOrID)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var commits []models.Commit
+	err = json.NewDecoder(res.Body).Decode(&commits)
+	if err != nil {
+		return nil, console.Error("Failed to parse commits: %v", err)
+	}
+
+	sort.Slice(commits, func(i, j int) bool {
+		return commits[i].Index < commits[j].Index
+	})
+
+	return commits, nil
+}
+
+// Get up to `limit` commits made on the specified branch before a commit index, newest first.
+func GetBranchCommitsBefore(projectConfig models.ProjectConfig, branchNameOrID string, index int, limit int) ([]models.Commit, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/branches/%s/commits?before=%d&limit=%d", config.I.VCS.ServerHost, projectConfig.ProjectSlug, branchNameOrID, index, limit)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var commits []models.Commit
+	err = json.NewDecoder(res.Body).Decode(&commits)
+	if err != nil {
+		return nil, console.Error("Failed to parse commits: %v", err)
+	}
+
+	// Don't rely on the server having applied the filters
+	commits = lo.Filter(commits, func(c models.Commit, _ int) bool {
+		return c.Index < index
+	})
+	sort.Slice(commits, func(i, j int) bool {
+		return commits[i].Index > commits[j].Index
+	})
+	if len(commits) > limit {
+		commits = commits[:limit]
+	}
+
+	return commits, nil
+}
+
+// Result of `vcs.GetBranchDivergence()`
+type BranchDivergence struct {
+	// Commit the two branches diverged from.
+	Base models.Commit
+	// Commits made on "ours" since the branches diverged, sorted by index.
+	Ours []models.Commit
+	// Commits made on "theirs" since the branches diverged, sorted by index.
+	Theirs []models.Commit
+}
+
+// Part of a branch's ancestry: the commits of a branch up to and including `MaxIndex`.
+type branchSegment struct {
+	BranchID string
+	MaxIndex int
+}
+
+// Fetches the branches and commits needed to walk branch ancestries, each only once, so multiple
+// branches can be compared without downloading the same commits again.
+type BranchCache struct {
+	projectConfig models.ProjectConfig
+	branches      map[string]models.Branch
+	commits       map[string][]models.Commit
+	firstCommit   *models.Commit
+}
+
+func NewBranchCache(projectConfig models.ProjectConfig) *BranchCache {
+	return &BranchCache{
+		projectConfig: projectConfig,
+		branches:      make(map[string]models.Branch),
+		commits:       make(map[string][]models.Commit),
+	}
+}
+
+func (cache *BranchCache) getBranch(branchNameOrID string) (models.Branch, error) {
+	if branch, ok := cache.branches[branchNameOrID]; ok {
+		return branch, nil
+	}
+
+	branch, err := GetBranch(cache.projectConfig, branchNameOrID)
+	if err != nil {
+		return models.Branch{}, err
+	}
+
+	cache.branches[branch.ID] = branch
+	cache.branches[branch.Name] = branch
+	return branch, nil
+}
+
+func (cache *BranchCache) getBranchCommits(branchID string) ([]models.Commit, error) {
+	if commits, ok := cache.commits[branchID]; ok {
+		return commits, nil
+	}
+
+	commits, err := GetBranchCommits(cache.projectConfig, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(commits, func(i, j int) bool {
+		return commits[i].Index < commits[j].Index
+	})
+	cache.commits[branchID] = commits
+	return commits, nil
+}
+
+func (cache *BranchCache) getCommit(index int) (models.Commit, error) {
+	for _, commits := range cache.commits {
+		for _, c := range commits {
+			if c.Index == index {
+				return c, nil
+			}
+		}
+	}
+
+	return GetCommit(cache.projectConfig, index)
+}
+
+// Get the segments of a branch's ancestry, starting with the branch itself and following the
+// recorded fork points back to the project's first branch.
+func (cache *BranchCache) getAncestry(branch models.Branch) ([]branchSegment, error) {
+	ancestry := []branchSegment{{BranchID: branch.ID, MaxIndex: math.MaxInt}}
+	visited := map[string]bool{branch.ID: true}
+
+	for {
+		if branch.ForkCommitIndex == 0 {
+			// Only the project's first branch may have no fork point
+			if cache.firstCommit == nil {
+				firstCommit, err := GetCommit(cache.projectConfig, 1)
+				if err != nil {
+					return nil, err
+				}
+				cache.firstCommit = &firstCommit
+			}
+
+			if cache.firstCommit.BranchID != branch.ID {
+				return nil, console.Error("Cannot determine where branch \"%s\" was created from since it has no recorded fork point", branch.Name)
+			}
+
+			return ancestry, nil
+		}
+
+		forkCommit, err := cache.getCommit(branch.ForkCommitIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		if visited[forkCommit.BranchID] {
+			return nil, console.Error("Branch \"%s\" has a circular ancestry", branch.Name)
+		}
+		visited[forkCommit.BranchID] = true
+
+		ancestry = append(ancestry, branchSegment{BranchID: forkCommit.BranchID, MaxIndex: forkCommit.Index})
+		branch, err = cache.getBranch(forkCommit.BranchID)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Get the commits of a branch's ancestry made after a commit index, sorted by index.
+func (cache *BranchCache) getCommitsSince(ancestry []branchSegment, index int) ([]models.Commit, error) {
+	res := []models.Commit{}
+	for _, seg := range ancestry {
+		commits, err := cache.getBranchCommits(seg.BranchID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range commits {
+			if c.Index > index && c.Index <= seg.MaxIndex {
+				res = append(res, c)
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Index < res[j].Index
+	})
+	return res, nil
+}
+
+// Get the commits made between an ancestor of a commit and the commit itself (both excluded),
+// following fork points back from the commit's branch, sorted by index.
+//
+// Returns an error if `ancestor` isn't an ancestor of `commit`.
+func (cache *BranchCache) GetCommitsBetween(ancestor models.Commit, commit models.Commit) ([]models.Commit, error) {
+	branch, err := cache.getBranch(commit.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestry, err := cache.getAncestry(branch)
+	if err != nil {
+		return nil, err
+	}
+	ancestry[0].MaxIndex = commit.Index - 1
+
+	_, ok := lo.Find(ancestry, func(seg branchSegment) bool {
+		return seg.BranchID == ancestor.BranchID && ancestor.Index <= seg.MaxIndex
+	})
+	if !ok {
+		return nil, console.Error("Commit #%d is not an ancestor of commit #%d", ancestor.Index, commit.Index)
+	}
+
+	return cache.getCommitsSince(ancestry, ancestor.Index)
+}
+
+// Same as `vcs.GetBranchDivergence()`, reusing previously fetched branches and commits.
+func (cache *BranchCache) GetBranchDivergence(oursName string, theirsName string) (BranchDivergence, error) {
+	ours, err := cache.getBranch(oursName)
+	if err != nil {
+		return BranchDivergence{}, err
+	}
+
+	theirs, err := cache.getBranch(theirsName)
+	if err != nil {
+		return BranchDivergence{}, err
+	}
+
+	if ours.ID == theirs.ID {
+		return BranchDivergence{}, console.Error("Cannot compare branch \"%s\" with itself", ours.Name)
+	}
+
+	oursAncestry, err := cache.getAncestry(ours)
+	if err != nil {
+		return BranchDivergence{}, err
+	}
+
+	theirsAncestry, err := cache.getAncestry(theirs)
+	if err != nil {
+		return BranchDivergence{}, err
+	}
+
+	// The base is the latest commit both ancestries contain
+	baseIndex := 0
+	for _, o := range oursAncestry {
+		for _, t := range theirsAncestry {
+			if o.BranchID == t.BranchID {
+				baseIndex = lo.Max([]int{baseIndex, lo.Min([]int{o.MaxIndex, t.MaxIndex})})
+			}
+		}
+	}
+
+	if baseIndex == 0 {
+		return BranchDivergence{}, console.Error("Branches \"%s\" and \"%s\" have no common commit", ours.Name, theirs.Name)
+	}
+
+	res := BranchDivergence{}
+	res.Base, err = cache.getCommit(baseIndex)
+	if err != nil {
+		return BranchDivergence{}, err
+	}
+
+	res.Ours, err = cache.getCommitsSince(oursAncestry, baseIndex)
+	if err != nil {
+		return BranchDivergence{}, err
+	}
+
+	res.Theirs, err = cache.getCommitsSince(theirsAncestry, baseIndex)
+	if err != nil {
+		return BranchDivergence{}, err
+	}
+
+	console.Verbose("Branches \"%s\" and \"%s\" diverged from commit #%d", ours.Name, theirs.Name, res.Base.Index)
+	return res, nil
+}
+
+// Determine where two branches diverged and which commits were made on each since then, following
+// the fork points recorded when the branches were created.
+//
+// Returns an error if either branch's ancestry can't be determined.
+func GetBranchDivergence(projectConfig models.ProjectConfig, oursName string, theirsName string) (BranchDivergence, error) {
+	return NewBranchCache(projectConfig).GetBranchDivergence(oursName, theirsName)
+}
+
+// Get a branch by name or ID, including its latest commit.
+func GetBranchWithCommit(projectConfig models.ProjectConfig, branchNameOrID string) (models.BranchWithCommit, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/branches/%s?join_commit=true", config.I.VCS.ServerHost, projectConfig.ProjectSlug, branchNameOrID)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return models.BranchWithCommit{}, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return models.BranchWithCommit{}, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return models.BranchWithCommit{}, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var branch models.BranchWithCommit
+	err = json.NewDecoder(res.Body).Decode(&branch)
+	if err != nil {
+		return models.BranchWithCommit{}, console.Error("Failed to parse branch: %v", err)
+	}
+
+	return branch, nil
+}
+
+// Get all branches in the project, including their latest commits.
+func GetBranches(projectConfig models.ProjectConfig) ([]models.BranchWithCommit, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/branches?join_commit=true", config.I.VCS.ServerHost, projectConfig.ProjectSlug)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var branches []models.BranchWithCommit
+	err = json.NewDecoder(res.Body).Decode(&branches)
+	if err != nil {
+		return nil, console.Error("Failed to parse branches: %v", err)
+	}
+
+	return branches, nil
+}
+
+// Get the commits made on a branch whose changes aren't in any other branch.
+//
+// Merges aren't recorded, so a file changed by the branch is considered merged if the latest commit
+// of another branch has the same version of it. Only commits that changed unmerged files are
+// returned.
+func GetUnmergedBranchCommits(projectConfig models.ProjectConfig, branch models.BranchWithCommit) ([]models.Commit, error) {
+	commits, err := GetBranchCommits(projectConfig, branch.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return commits, nil
+	}
+
+	sort.Slice(commits, func(i, j int) bool {
+		return commits[i].Index < commits[j].Index
+	})
+
+	// Files changed by the branch since the commit it was created from
+	base := models.Commit{}
+	if branch.ForkCommitIndex != 0 {
+		base, err = GetCommit(projectConfig, branch.ForkCommitIndex)
+		if err != nil {
+			return nil, err
+		}
+	} else if commits[0].Index != 1 {
+		return nil, console.Error("Cannot determine which changes of branch \"%s\" are unmerged since it has no recorded fork point", branch.Name)
+	}
+
+	head := commits[len(commits)-1]
+	changedPaths := []string{}
+	for path, fileData := range head.Files {
+		if base.Files[path].Hash != fileData.Hash {
+			changedPaths = append(changedPaths, path)
+		}
+	}
+	for path := range base.Files {
+		if _, ok := head.Files[path]; !ok {
+			changedPaths = append(changedPaths, path)
+		}
+	}
+
+	branches, err := GetBranches(projectConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Changed files that no other branch has the same version of
+	unmergedPaths := make(map[string]bool)
+	for _, path := range changedPaths {
+		unmergedPaths[path] = true
+	}
+	for _, other := range branches {
+		if other.ID == branch.ID {
+			continue
+		}
+
+		for path := range unmergedPaths {
+			otherFileData, otherOk := other.Commit.Files[path]
+			fileData, ok := head.Files[path]
+			if otherOk == ok && otherFileData.Hash == fileData.Hash {
+				console.Verbose("Changes of branch \"%s\" to %s were found in branch \"%s\"", branch.Name, path, other.Name)
+				delete(unmergedPaths, path)
+			}
+		}
+	}
+
+	return lo.Filter(commits, func(c models.Commit, _ int) bool {
+		for _, path := range append(append(append([]string{}, c.CreatedFiles...), c.ModifiedFiles...), c.DeletedFiles...) {
+			if unmergedPaths[path] {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// Restore a deleted branch.
+func RestoreBranch(projectConfig models.ProjectConfig, name string) (models.Branch, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/branches/%s/restore", config.I.VCS.ServerHost, projectConfig.ProjectSlug, name)
+	req, err := http.NewRequest("POST", reqUrl, nil)
+	if err != nil {
+		return models.Branch{}, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return models.Branch{}, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return models.Branch{}, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var branch models.Branch
+	err = json.NewDecoder(res.Body).Decode(&branch)
+	if err != nil {
+		return models.Branch{}, console.Error("Failed to parse branch: %v", err)
+	}
+
+	return branch, nil
+}
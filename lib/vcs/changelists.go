@@ -0,0 +1,195 @@
+package vcs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/system"
+	"github.com/decentvcs/cli/models"
+	"github.com/samber/lo"
+)
+
+// Get all changelists in the working copy, sorted by name.
+func GetChangelists() ([]models.Changelist, error) {
+	changelistsPath, err := getChangelistsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	changelistsBytes, err := os.ReadFile(changelistsPath)
+	if os.IsNotExist(err) {
+		return []models.Changelist{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var changelists []models.Changelist
+	if err = json.Unmarshal(changelistsBytes, &changelists); err != nil {
+		return nil, console.Error("Failed to parse changelists: %v", err)
+	}
+
+	return changelists, nil
+}
+
+// Get a changelist by name.
+func GetChangelist(name string) (models.Changelist, error) {
+	changelists, err := GetChangelists()
+	if err != nil {
+		return models.Changelist{}, err
+	}
+
+	changelist, ok := lo.Find(changelists, func(cl models.Changelist) bool {
+		return cl.Name == name
+	})
+	if !ok {
+		return models.Changelist{}, console.Error("Changelist \"%s\" not found (run `dvcs changelist list` to list changelists)", name)
+	}
+
+	return changelist, nil
+}
+
+// Create a new empty changelist.
+func CreateChangelist(name string, description string) (models.Changelist, error) {
+	changelists, err := GetChangelists()
+	if err != nil {
+		return models.Changelist{}, err
+	}
+
+	if lo.ContainsBy(changelists, func(cl models.Changelist) bool { return cl.Name == name }) {
+		return models.Changelist{}, console.Error("Changelist \"%s\" already exists", name)
+	}
+
+	changelist := models.Changelist{
+		Name:        name,
+		Description: description,
+		CreatedAt:   time.Now(),
+		Paths:       []string{},
+	}
+
+	if err = saveChangelists(append(changelists, changelist)); err != nil {
+		return models.Changelist{}, err
+	}
+
+	return changelist, nil
+}
+
+// Add files or directories (relative fs paths) to a changelist.
+// A path can only be in one changelist at a time, so it's moved out of any other changelist. Paths
+// inside a directory that's in another changelist are rejected, since they can't be moved out of it.
+func AddToChangelist(name string, paths []string) error {
+	changelists, err := GetChangelists()
+	if err != nil {
+		return err
+	}
+
+	_, index, ok := lo.FindIndexOf(changelists, func(cl models.Changelist) bool {
+		return cl.Name == name
+	})
+	if !ok {
+		return console.Error("Changelist \"%s\" not found (run `dvcs changelist list` to list changelists)", name)
+	}
+
+	for i, cl := range changelists {
+		if i == index {
+			continue
+		}
+
+		for _, path := range paths {
+			if lo.ContainsBy(cl.Paths, func(p string) bool { return p != path && PathMatches(path, []string{p}) }) {
+				return console.Error("\"%s\" is inside a directory in changelist \"%s\". Remove the directory from it first.", path, cl.Name)
+			}
+		}
+
+		// Move paths (and anything inside them) out of the other changelist
+		moved := lo.Filter(cl.Paths, func(p string, _ int) bool {
+			return PathMatches(p, paths)
+		})
+		for _, p := range moved {
+			console.Warning("Moving \"%s\" out of changelist \"%s\"", p, cl.Name)
+		}
+		changelists[i].Paths = lo.Without(cl.Paths, moved...)
+	}
+
+	changelists[index].Paths = lo.Uniq(append(changelists[index].Paths, paths...))
+	sort.Strings(changelists[index].Paths)
+
+	return saveChangelists(changelists)
+}
+
+// Remove files or directories (relative fs paths) from whichever changelist they're in.
+func RemoveFromChangelists(paths []string) error {
+	changelists, err := GetChangelists()
+	if err != nil {
+		return err
+	}
+
+	for i := range changelists {
+		changelists[i].Paths = lo.Without(changelists[i].Paths, paths...)
+	}
+
+	return saveChangelists(changelists)
+}
+
+// Delete a changelist. The files in it are left untouched.
+func DeleteChangelist(name string) error {
+	changelists, err := GetChangelists()
+	if err != nil {
+		return err
+	}
+
+	remaining := lo.Filter(changelists, func(cl models.Changelist, _ int) bool {
+		return cl.Name != name
+	})
+	if len(remaining) == len(changelists) {
+		return console.Error("Changelist \"%s\" not found (run `dvcs changelist list` to list changelists)", name)
+	}
+
+	return saveChangelists(remaining)
+}
+
+// Get the name of the changelist a file (relative fs path) belongs to.
+// Returns an empty string if it doesn't belong to any.
+func GetFileChangelistName(changelists []models.Changelist, path string) string {
+	for _, cl := range changelists {
+		if len(cl.Paths) > 0 && PathMatches(path, cl.Paths) {
+			return cl.Name
+		}
+	}
+
+	return ""
+}
+
+// Write changelists to disk, sorted by name.
+func saveChangelists(changelists []models.Changelist) error {
+	changelistsPath, err := getChangelistsPath()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(changelists, func(i, j int) bool {
+		return changelists[i].Name < changelists[j].Name
+	})
+
+	changelistsJson, err := json.MarshalIndent(changelists, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return system.WriteFileAtomic(changelistsPath, changelistsJson, 0644)
+}
+
+// Returns the path to the changelists file.
+func getChangelistsPath() (string, error) {
+	dataDirPath, err := GetProjectDataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDirPath, constants.ChangelistsFileName), nil
+}
@@ -0,0 +1,127 @@
+package vcs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/system"
+	"github.com/decentvcs/cli/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Get the project's commit message config.
+//
+// If the project doesn't have a commit config file, returns an empty config.
+func GetCommitConfig() (models.CommitConfig, error) {
+	projectConfigPath, err := GetProjectConfigPath()
+	if err != nil {
+		return models.CommitConfig{}, err
+	}
+
+	configBytes, err := os.ReadFile(filepath.Join(filepath.Dir(projectConfigPath), constants.CommitConfigFileName))
+	if os.IsNotExist(err) {
+		return models.CommitConfig{}, nil
+	}
+	if err != nil {
+		return models.CommitConfig{}, err
+	}
+
+	var commitConfig models.CommitConfig
+	if err = yaml.Unmarshal(configBytes, &commitConfig); err != nil {
+		return models.CommitConfig{}, console.Error("Failed to parse %s: %v", constants.CommitConfigFileName, err)
+	}
+
+	return commitConfig, nil
+}
+
+// Make sure a commit message follows the project's rules.
+func ValidateCommitMessage(commitConfig models.CommitConfig, message string) error {
+	message = strings.TrimSpace(message)
+
+	if len(message) < commitConfig.MinLength {
+		return console.Error("Commit message must be at least %d characters long", commitConfig.MinLength)
+	}
+
+	if commitConfig.RequiredPattern != "" {
+		regex, err := regexp.Compile(commitConfig.RequiredPattern)
+		if err != nil {
+			return console.Error("Invalid required_pattern in %s: %v", constants.CommitConfigFileName, err)
+		}
+
+		if !regex.MatchString(message) {
+			return console.Error("Commit message must match the pattern \"%s\"", commitConfig.RequiredPattern)
+		}
+	}
+
+	return nil
+}
+
+// Open the user's text editor to write a commit message, prefilled with the project's template and
+// a list of the changes being pushed as comments.
+//
+// Returns the message with comment lines removed, or an empty string if the user didn't write one.
+func EditCommitMessage(commitConfig models.CommitConfig, branchName string, fc FileChangeDetectionResult) (string, error) {
+	dataDirPath, err := GetProjectDataDir()
+	if err != nil {
+		return "", err
+	}
+
+	messagePath := filepath.Join(dataDirPath, constants.CommitMessageFileName)
+	defer os.Remove(messagePath)
+
+	// Write template
+	var sb strings.Builder
+	sb.WriteString(commitConfig.Template)
+	if commitConfig.Template != "" && !strings.HasSuffix(commitConfig.Template, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString("# Write a commit message for your changes. Lines starting with \"#\" are ignored,\n")
+	sb.WriteString("# and an empty message aborts the push.\n")
+	sb.WriteString("#\n")
+	sb.WriteString(fmt.Sprintf("# Changes to be pushed to branch \"%s\":\n", branchName))
+	for _, path := range fc.CreatedFilePaths {
+		sb.WriteString(fmt.Sprintf("#   created:  %s\n", path))
+	}
+	for _, path := range fc.ModifiedFilePaths {
+		sb.WriteString(fmt.Sprintf("#   modified: %s\n", path))
+	}
+	for _, path := range fc.DeletedFilePaths {
+		sb.WriteString(fmt.Sprintf("#   deleted:  %s\n", path))
+	}
+
+	if err = os.WriteFile(messagePath, []byte(sb.String()), 0644); err != nil {
+		return "", err
+	}
+
+	// Open editor and wait for it to close
+	editor := system.GetEditorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], messagePath)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return "", console.Error("Failed to run editor \"%s\": %v", strings.Join(editor, " "), err)
+	}
+
+	messageBytes, err := os.ReadFile(messagePath)
+	if err != nil {
+		return "", err
+	}
+
+	// Remove comments
+	lines := []string{}
+	for _, line := range strings.Split(string(messageBytes), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
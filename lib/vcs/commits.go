@@ -1,6 +1,8 @@
 package vcs
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -9,41 +11,166 @@ import (
 	"github.com/decentvcs/cli/lib/console"
 	"github.com/decentvcs/cli/lib/httpvalidation"
 	"github.com/decentvcs/cli/models"
+	"github.com/samber/lo"
+	"golang.org/x/exp/maps"
 )
 
-// Delete all commits ahead of the given index for the specified branch.
-// All unique file uploads are immediately deleted (based on commit hash maps).
-func DeleteCommitsAheadOfIndex(projectConfig models.ProjectConfig, branchID string, index int) error {
-	// Delete all commits ahead of the given index for the specified branch
-	console.Verbose("Deleting commits ahead of commit #%d...", index)
+// Move all commits ahead of the given index from one branch to another, keeping them and their
+// objects alive. The server updates the latest commit of both branches.
+func MoveCommitsAheadOfIndex(projectConfig models.ProjectConfig, branchID string, index int, toBranchID string) error {
+	console.Verbose("Moving commits ahead of commit #%d...", index)
+	bodyJson, err := json.Marshal(map[string]string{"branch_id": toBranchID})
+	if err != nil {
+		return err
+	}
+
 	httpClient := http.Client{}
-	reqUrl := fmt.Sprintf("%s/projects/%s/branches/%s/commits?after=%d", config.I.VCS.ServerHost, projectConfig.ProjectSlug, branchID, index)
-	req, _ := http.NewRequest("DELETE", reqUrl, nil)
+	reqUrl := fmt.Sprintf("%s/projects/%s/branches/%s/commits/move?after=%d", config.I.VCS.ServerHost, projectConfig.ProjectSlug, branchID, index)
+	req, _ := http.NewRequest("POST", reqUrl, bytes.NewBuffer(bodyJson))
 	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	req.Header.Set("Content-Type", "application/json")
 	res, err := httpClient.Do(req)
 	if err != nil {
-		return console.Error("Failed to delete commits: %v", err)
+		return console.Error("Failed to move commits: %v", err)
 	}
 	if err := httpvalidation.ValidateResponse(res); err != nil {
 		return err
 	}
+	res.Body.Close()
+
+	return nil
+}
+
+// Delete all objects from storage that aren't referenced by any commit or shelf and are older than
+// the configured retention period.
+//
+// Shelves aren't commits, so the objects they reference are sent to the server to be kept.
+func PurgeUnusedObjects(projectConfig models.ProjectConfig) error {
+	shelves, err := GetShelves(projectConfig)
+	if err != nil {
+		return err
+	}
+
+	keepHashes := []string{}
+	for _, shelf := range shelves {
+		for _, fileData := range append(maps.Values(shelf.Files), maps.Values(shelf.BaseFiles)...) {
+			keepHashes = append(keepHashes, fileData.Hash)
+			keepHashes = append(keepHashes, fileData.PatchHashes...)
+		}
+	}
+
+	bodyJson, err := json.Marshal(map[string][]string{"keep_hashes": lo.Uniq(keepHashes)})
+	if err != nil {
+		return err
+	}
 
-	// Delete all unused objects from storage
 	console.Info("Deleting unused objects (this may take a while)...")
-	reqUrl = fmt.Sprintf("%s/projects/%s/storage/unused", config.I.VCS.ServerHost, projectConfig.ProjectSlug)
-	req, _ = http.NewRequest("DELETE", reqUrl, nil)
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/storage/unused?older_than_days=%d", config.I.VCS.ServerHost, projectConfig.ProjectSlug, config.I.VCS.Storage.PurgeRetentionDays)
+	req, _ := http.NewRequest("DELETE", reqUrl, bytes.NewBuffer(bodyJson))
 	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
-	res, err = httpClient.Do(req)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := httpClient.Do(req)
 	if err != nil {
 		return console.Error("Failed to delete unused objects: %v", err)
 	}
 	if err := httpvalidation.ValidateResponse(res); err != nil {
 		return err
 	}
+	res.Body.Close()
 
 	return nil
 }
 
+// Get a commit by index.
+func GetCommit(projectConfig models.ProjectConfig, index int) (models.Commit, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/commits/%d", config.I.VCS.ServerHost, projectConfig.ProjectSlug, index)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return models.Commit{}, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return models.Commit{}, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return models.Commit{}, console.Error("Failed to get commit #%d: %v", index, err)
+	}
+	defer res.Body.Close()
+
+	// Parse commit
+	var commit models.Commit
+	err = json.NewDecoder(res.Body).Decode(&commit)
+	if err != nil {
+		return models.Commit{}, console.Error("Failed to parse commit: %v", err)
+	}
+
+	return commit, nil
+}
+
+// Get a page of commits across all branches, newest first.
+//
+// The server skips the newest `offset` commits. Servers without offset support return the newest
+// commits regardless, so callers paging through commits must stop once no older ones are returned.
+func GetCommits(projectConfig models.ProjectConfig, limit int, offset int) ([]models.CommitWithBranch, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/commits?limit=%d&offset=%d", config.I.VCS.ServerHost, projectConfig.ProjectSlug, limit, offset)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var commits []models.CommitWithBranch
+	err = json.NewDecoder(res.Body).Decode(&commits)
+	if err != nil {
+		return nil, console.Error("Failed to parse commits: %v", err)
+	}
+
+	return commits, nil
+}
+
+// Get the commit preceding the specified one, i.e. the previous commit on the same branch, or the
+// commit the branch was created from if it's the first commit on its branch.
+//
+// Returns a commit with an index of 0 if it's the first commit of the project, or an error if the
+// branch's fork point wasn't recorded.
+func GetParentCommit(projectConfig models.ProjectConfig, commit models.Commit) (models.Commit, error) {
+	if commit.Index <= 1 {
+		return models.Commit{}, nil
+	}
+
+	previousCommits, err := GetBranchCommitsBefore(projectConfig, commit.BranchID, commit.Index, 1)
+	if err != nil {
+		return models.Commit{}, err
+	}
+	if len(previousCommits) > 0 {
+		return previousCommits[0], nil
+	}
+
+	branch, err := GetBranch(projectConfig, commit.BranchID)
+	if err != nil {
+		return models.Commit{}, err
+	}
+
+	if branch.ForkCommitIndex == 0 {
+		return models.Commit{}, console.Error("Cannot determine the parent of commit #%d since branch \"%s\" has no recorded fork point", commit.Index, branch.Name)
+	}
+
+	return GetCommit(projectConfig, branch.ForkCommitIndex)
+}
+
 func FileMapToHashMap(fileMap map[string]models.FileData) map[string]string {
 	hashMap := make(map[string]string)
 	for path, file := range fileMap {
@@ -0,0 +1,279 @@
+package vcs
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffInsert
+	DiffDelete
+)
+
+// Line in a diff between two texts.
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// Max amount of edits searched for before giving up and treating the remaining lines as entirely
+// replaced. Keeps memory usage bounded for very different files.
+const maxDiffEdits = 4096
+
+// Split text into lines, without line endings.
+func SplitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Calculate the line diff between two texts using the Myers diff algorithm.
+func DiffLines(a []string, b []string) []DiffLine {
+	res := []DiffLine{}
+
+	// Trim common prefix and suffix
+	prefixLen := 0
+	for prefixLen < len(a) && prefixLen < len(b) && a[prefixLen] == b[prefixLen] {
+		res = append(res, DiffLine{Op: DiffEqual, Text: a[prefixLen]})
+		prefixLen++
+	}
+	a, b = a[prefixLen:], b[prefixLen:]
+
+	suffixLen := 0
+	for suffixLen < len(a) && suffixLen < len(b) && a[len(a)-1-suffixLen] == b[len(b)-1-suffixLen] {
+		suffixLen++
+	}
+	suffix := a[len(a)-suffixLen:]
+	a, b = a[:len(a)-suffixLen], b[:len(b)-suffixLen]
+
+	res = append(res, myersDiff(a, b)...)
+
+	for _, line := range suffix {
+		res = append(res, DiffLine{Op: DiffEqual, Text: line})
+	}
+
+	return res
+}
+
+// Myers diff algorithm.
+// Each step of the search is traced so the shortest edit script can be backtracked afterwards.
+func myersDiff(a []string, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return []DiffLine{}
+	}
+
+	// V is indexed by diagonal k (in -max..max), offset to be non-negative
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	trace := [][]int{}
+	found := false
+
+	for d := 0; d <= max && d <= maxDiffEdits; d++ {
+		// Save diagonals that can be referenced when backtracking this step
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+
+		if found {
+			break
+		}
+	}
+
+	if !found {
+		// Too many edits, treat as replaced
+		res := []DiffLine{}
+		for _, line := range a {
+			res = append(res, DiffLine{Op: DiffDelete, Text: line})
+		}
+		for _, line := range b {
+			res = append(res, DiffLine{Op: DiffInsert, Text: line})
+		}
+		return res
+	}
+
+	// Backtrack from the end to build the edit script in reverse
+	reversed := []DiffLine{}
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		snapshot := trace[d]
+		getV := func(k int) int {
+			return snapshot[k+d+1]
+		}
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && getV(k-1) < getV(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := getV(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, DiffLine{Op: DiffEqual, Text: a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, DiffLine{Op: DiffInsert, Text: b[y-1]})
+			} else {
+				reversed = append(reversed, DiffLine{Op: DiffDelete, Text: a[x-1]})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	res := make([]DiffLine, len(reversed))
+	for i, line := range reversed {
+		res[len(reversed)-1-i] = line
+	}
+
+	return res
+}
+
+// Count inserted and deleted lines in a diff.
+func CountDiffLines(lines []DiffLine) (insertions int, deletions int) {
+	for _, line := range lines {
+		switch line.Op {
+		case DiffInsert:
+			insertions++
+		case DiffDelete:
+			deletions++
+		}
+	}
+
+	return insertions, deletions
+}
+
+// Format a diff as unified diff hunks (starting with "@@" headers), with the specified amount of
+// context lines around each change.
+//
+// Returns the lines of the hunks. Empty if there are no changes.
+func FormatUnifiedDiff(lines []DiffLine, context int) []string {
+	res := []string{}
+
+	// Find ranges of lines to include in hunks
+	i := 0
+	for i < len(lines) {
+		if lines[i].Op == DiffEqual {
+			i++
+			continue
+		}
+
+		// Start of a hunk
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// Extend hunk while changes are within 2 * context lines of each other
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].Op != DiffEqual {
+				end = j
+			} else if j-end > 2*context {
+				break
+			}
+		}
+		end += context
+		if end >= len(lines) {
+			end = len(lines) - 1
+		}
+
+		// Calculate line numbers at the start of the hunk
+		oldStart, newStart := 1, 1
+		for _, line := range lines[:start] {
+			if line.Op != DiffInsert {
+				oldStart++
+			}
+			if line.Op != DiffDelete {
+				newStart++
+			}
+		}
+
+		hunk := []string{}
+		oldCount, newCount := 0, 0
+		for _, line := range lines[start : end+1] {
+			switch line.Op {
+			case DiffEqual:
+				hunk = append(hunk, " "+line.Text)
+				oldCount++
+				newCount++
+			case DiffDelete:
+				hunk = append(hunk, "-"+line.Text)
+				oldCount++
+			case DiffInsert:
+				hunk = append(hunk, "+"+line.Text)
+				newCount++
+			}
+		}
+
+		// Empty sides start at line 0, like in `diff -u`
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+
+		res = append(res, fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount))
+		res = append(res, hunk...)
+		i = end + 1
+	}
+
+	return res
+}
+
+// Detect the MIME type of a file from its contents.
+func DetectFileType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, err := file.Read(buf)
+	if err != nil && n == 0 {
+		// Empty file
+		return "application/octet-stream", nil
+	}
+
+	return http.DetectContentType(buf[:n]), nil
+}
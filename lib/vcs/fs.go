@@ -19,11 +19,9 @@ import (
 	"github.com/decentvcs/cli/constants"
 	"github.com/decentvcs/cli/lib/console"
 	"github.com/decentvcs/cli/lib/httpvalidation"
-	"github.com/decentvcs/cli/lib/storage"
 	"github.com/decentvcs/cli/lib/util"
 	"github.com/decentvcs/cli/models"
 	"github.com/samber/lo"
-	"golang.org/x/exp/maps"
 )
 
 // Get file hash. Can be used to detect file changes.
@@ -83,6 +81,11 @@ func CalculateHashes(rootPath string) (map[string]string, error) {
 			return err
 		}
 
+		// Skip project data directory
+		if info.IsDir() && info.Name() == constants.ProjectDataDirName {
+			return filepath.SkipDir
+		}
+
 		// Skip directories and project file
 		if info.IsDir() || filepath.Base(path) == constants.ProjectFileName {
 			return nil
@@ -124,18 +127,73 @@ type FileChangeDetectionResult struct {
 	FileDataMap       map[string]models.FileData
 }
 
+type DetectFileChangesOptions struct {
+	// If true, nothing is printed.
+	Quiet bool
+	// Relative fs paths of files or directories to limit detection to. If empty, all files are
+	// included.
+	Paths []string
+}
+
+// Don't print progress or detected changes.
+func WithQuiet() func(*DetectFileChangesOptions) {
+	return func(o *DetectFileChangesOptions) {
+		o.Quiet = true
+	}
+}
+
+// Only detect changes to the specified files or directories (relative fs paths).
+// Files outside of them keep their data from the current commit in the result's file data map.
+func WithPaths(paths []string) func(*DetectFileChangesOptions) {
+	return func(o *DetectFileChangesOptions) {
+		o.Paths = paths
+	}
+}
+
+// Returns true if the relative fs path is one of the specified paths or inside one of them.
+// Always true if no paths are specified.
+func PathMatches(path string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+
+	for _, p := range paths {
+		if p == "." || path == p || strings.HasPrefix(path, p+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Detect file changes.
 //
 // @param currentHashMap - Hash map of current commit fetched from remote
-func DetectFileChanges(files map[string]models.FileData) (FileChangeDetectionResult, error) {
-	console.Info("Checking for changes...")
+func DetectFileChanges(files map[string]models.FileData, opts ...func(*DetectFileChangesOptions)) (FileChangeDetectionResult, error) {
+	// Build options
+	o := &DetectFileChangesOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
 
-	// Get known file paths in current commit
-	remainingPaths := maps.Keys(files)
+	if !o.Quiet {
+		console.Info("Checking for changes...")
+	}
 
 	createdFilePaths := []string{}
 	modifiedFilePaths := []string{}
 	newFileDataMap := make(map[string]models.FileData)
+
+	// Get known file paths in current commit, keeping files outside of the specified paths as is
+	remainingPaths := []string{}
+	for path, fileData := range files {
+		if PathMatches(path, o.Paths) {
+			remainingPaths = append(remainingPaths, path)
+		} else {
+			newFileDataMap[path] = fileData
+		}
+	}
+
 	fileInfoMap := make(map[string]os.FileInfo)
 
 	createdFileSizeTotal := int64(0)
@@ -159,6 +217,11 @@ func DetectFileChanges(files map[string]models.FileData) (FileChangeDetectionRes
 			return err
 		}
 
+		// Skip project data directory
+		if dir.IsDir() && dir.Name() == constants.ProjectDataDirName {
+			return filepath.SkipDir
+		}
+
 		// Skip directories and the project file
 		if dir.IsDir() || filepath.Base(path) == constants.ProjectFileName {
 			return nil
@@ -176,6 +239,11 @@ func DetectFileChanges(files map[string]models.FileData) (FileChangeDetectionRes
 			}
 		}
 
+		relPath, _ := filepath.Rel(projectPath, path)
+		if !PathMatches(relPath, o.Paths) {
+			return nil
+		}
+
 		// Calculate file hash
 		newHash, err := GetFileHash(path)
 		if err != nil {
@@ -188,11 +256,10 @@ func DetectFileChanges(files map[string]models.FileData) (FileChangeDetectionRes
 			return err
 		}
 
-		relPath, _ := filepath.Rel(projectPath, path)
 		remoteFileData := files[relPath]
 
 		// Determine remote file version
-		var version uint8 = 1
+		var version uint32 = 1
 		if remoteFileData.Version > 1 {
 			version = remoteFileData.Version
 		}
@@ -202,6 +269,7 @@ func DetectFileChanges(files map[string]models.FileData) (FileChangeDetectionRes
 			Hash:        newHash,
 			PatchHashes: files[relPath].PatchHashes,
 			Version:     version,
+			Size:        fileInfo.Size(),
 		}
 		fileInfoMap[relPath] = fileInfo
 
@@ -234,32 +302,34 @@ func DetectFileChanges(files map[string]models.FileData) (FileChangeDetectionRes
 	}
 
 	// Print result
-	if len(createdFilePaths) > 0 {
-		fmt.Println(color.InGreen(color.InBold("Created files:")))
-		for _, fp := range createdFilePaths {
-			fileInfo := fileInfoMap[fp]
-			fileSize := fileInfo.Size()
-			createdFileSizeTotal += fileSize
-			fmt.Printf(color.InGreen("  + %s (%s)\n"), fp, util.FormatBytesSize(fileSize))
-		}
+	if !o.Quiet {
+		if len(createdFilePaths) > 0 {
+			fmt.Println(color.InGreen(color.InBold("Created files:")))
+			for _, fp := range createdFilePaths {
+				fileInfo := fileInfoMap[fp]
+				fileSize := fileInfo.Size()
+				createdFileSizeTotal += fileSize
+				fmt.Printf(color.InGreen("  + %s (%s)\n"), fp, util.FormatBytesSize(fileSize))
+			}
 
-		fmt.Printf(color.InGreen("  Total: %s\n"), util.FormatBytesSize(createdFileSizeTotal))
-	}
-	if len(modifiedFilePaths) > 0 {
-		console.Info(color.InBlue(color.InBold("Modified files:")))
-		for _, fp := range modifiedFilePaths {
-			fileInfo := fileInfoMap[fp]
-			fileSize := fileInfo.Size()
-			modifiedFileSizeTotal += fileSize
-			fmt.Printf(color.InBlue("  * %s (%s)\n"), fp, util.FormatBytesSize(fileSize))
+			fmt.Printf(color.InGreen("  Total: %s\n"), util.FormatBytesSize(createdFileSizeTotal))
 		}
+		if len(modifiedFilePaths) > 0 {
+			console.Info(color.InBlue(color.InBold("Modified files:")))
+			for _, fp := range modifiedFilePaths {
+				fileInfo := fileInfoMap[fp]
+				fileSize := fileInfo.Size()
+				modifiedFileSizeTotal += fileSize
+				fmt.Printf(color.InBlue("  * %s (%s)\n"), fp, util.FormatBytesSize(fileSize))
+			}
 
-		console.Info(color.InBlue("  Total: %s\n"), util.FormatBytesSize(modifiedFileSizeTotal))
-	}
-	if len(remainingPaths) > 0 {
-		console.Info(color.InRed(color.InBold("Deleted files:")))
-		for _, fp := range remainingPaths {
-			fmt.Printf(color.InRed("  - %s\n"), fp)
+			console.Info(color.InBlue("  Total: %s\n"), util.FormatBytesSize(modifiedFileSizeTotal))
+		}
+		if len(remainingPaths) > 0 {
+			console.Info(color.InRed(color.InBold("Deleted files:")))
+			for _, fp := range remainingPaths {
+				fmt.Printf(color.InRed("  - %s\n"), fp)
+			}
 		}
 	}
 
@@ -342,12 +412,10 @@ func ResetChanges(confirm bool) error {
 		}
 	}
 
-	// Delete all created files
-	for _, path := range fc.CreatedFilePaths {
-		err = os.Remove(path)
-		if err != nil {
-			return console.Error("Failed to delete file \"%s\": %s", path, err)
-		}
+	// Back up created and modified files, since their contents can't be downloaded again
+	_, err = BackupFiles(projectConfig, "reset", append(append([]string{}, fc.CreatedFilePaths...), fc.ModifiedFilePaths...), commit.Files)
+	if err != nil {
+		return err
 	}
 
 	// Build file data map for overridden files (modified + deleted)
@@ -358,10 +426,14 @@ func ResetChanges(confirm bool) error {
 		overrideHashMap[path] = hash
 	}
 
-	// Download remote versions of modified and deleted files
-	err = storage.DownloadMany(projectConfig, ".", overrideHashMap)
+	// Delete all created files and download remote versions of modified and deleted files
+	err = ApplyWorkingCopyUpdate(projectConfig, WorkingCopyUpdate{
+		Operation: "reset",
+		Downloads: overrideHashMap,
+		Deletes:   fc.CreatedFilePaths,
+	})
 	if err != nil {
-		return console.Error("Failed to download files: %s", err)
+		return console.Error("Failed to reset files: %s", err)
 	}
 
 	return nil
@@ -0,0 +1,234 @@
+package vcs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/storage"
+	"github.com/decentvcs/cli/lib/system"
+	"github.com/decentvcs/cli/models"
+	"golang.org/x/exp/maps"
+)
+
+// Update to apply to the working copy with `vcs.ApplyWorkingCopyUpdate()`.
+type WorkingCopyUpdate struct {
+	// Name of the operation performing the update (e.g. "sync").
+	Operation string
+	// Map of relative fs paths to hashes of files to download into the working copy.
+	Downloads map[string]string
+	// Map of relative fs paths to local files (e.g. merge results) to copy into the working copy.
+	Files map[string]string
+	// Relative fs paths of files to delete from the working copy.
+	Deletes []string
+	// Commit index to save in the project config once applied. 0 leaves it unchanged.
+	CommitIndex int
+}
+
+// Apply an update to the working copy transactionally.
+//
+// All new file contents are staged in the project data directory first, then an on-disk journal is
+// written once and staged files are moved into place with atomic renames. If the process is
+// interrupted, `vcs.RecoverWorkingCopyUpdate()` rolls back the update if there's no journal yet, or
+// completes it if there is.
+func ApplyWorkingCopyUpdate(projectConfig models.ProjectConfig, update WorkingCopyUpdate) error {
+	journalPath, err := getJournalPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(journalPath); err == nil {
+		return console.Error("Another update to the working copy is in progress or was interrupted. Run the command again to recover it.")
+	}
+
+	stagingDirPath, err := getStagingDirPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(stagingDirPath); err == nil {
+		return console.Error("Another update to the working copy is in progress or was interrupted. Run the command again to recover it.")
+	}
+
+	if err = os.MkdirAll(stagingDirPath, 0755); err != nil {
+		return err
+	}
+
+	// Stage new file contents
+	if len(update.Downloads) > 0 {
+		err = storage.DownloadManyCached(projectConfig, stagingDirPath, update.Downloads)
+		if err != nil {
+			rollBackWorkingCopyUpdate(journalPath, stagingDirPath)
+			return err
+		}
+	}
+
+	for relPath, srcPath := range update.Files {
+		err = system.CopyFile(srcPath, filepath.Join(stagingDirPath, relPath))
+		if err != nil {
+			rollBackWorkingCopyUpdate(journalPath, stagingDirPath)
+			return console.Error("Failed to stage file \"%s\": %v", relPath, err)
+		}
+	}
+
+	// Everything is staged, so from here on the update is completed if interrupted
+	journal := models.WorkingCopyJournal{
+		State:       models.JournalStateApplying,
+		Operation:   update.Operation,
+		Writes:      append(maps.Keys(update.Downloads), maps.Keys(update.Files)...),
+		Deletes:     update.Deletes,
+		CommitIndex: update.CommitIndex,
+	}
+	if journal.Deletes == nil {
+		journal.Deletes = []string{}
+	}
+
+	if err = writeJournal(journalPath, journal); err != nil {
+		rollBackWorkingCopyUpdate(journalPath, stagingDirPath)
+		return err
+	}
+
+	return applyJournal(journalPath, stagingDirPath, journal)
+}
+
+// Complete or roll back a working copy update that was interrupted.
+// Does nothing if the current directory isn't a project or no update was interrupted.
+func RecoverWorkingCopyUpdate() error {
+	if _, err := GetProjectConfigPath(); err != nil {
+		return nil
+	}
+
+	journalPath, err := getJournalPath()
+	if err != nil {
+		return err
+	}
+
+	stagingDirPath, err := getStagingDirPath()
+	if err != nil {
+		return err
+	}
+
+	journalBytes, err := os.ReadFile(journalPath)
+	if os.IsNotExist(err) {
+		if _, err := os.Stat(stagingDirPath); err != nil {
+			return nil
+		}
+
+		// Interrupted while staging, so the working copy wasn't touched yet
+		console.Warning("Rolling back interrupted working copy update...")
+		return rollBackWorkingCopyUpdate(journalPath, stagingDirPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	var journal models.WorkingCopyJournal
+	if err = json.Unmarshal(journalBytes, &journal); err != nil || journal.State != models.JournalStateApplying {
+		// Working copy wasn't touched yet
+		console.Warning("Rolling back interrupted %s...", journal.Operation)
+		return rollBackWorkingCopyUpdate(journalPath, stagingDirPath)
+	}
+
+	console.Warning("Completing interrupted %s...", journal.Operation)
+	return applyJournal(journalPath, stagingDirPath, journal)
+}
+
+// Move staged files into the working copy, delete files, and update the project config.
+// Safe to call repeatedly for the same journal.
+func applyJournal(journalPath string, stagingDirPath string, journal models.WorkingCopyJournal) error {
+	projectConfigPath, err := GetProjectConfigPath()
+	if err != nil {
+		return err
+	}
+
+	projectPath := filepath.Dir(projectConfigPath)
+
+	console.Verbose("Applying %d writes and %d deletes to working copy...", len(journal.Writes), len(journal.Deletes))
+	for _, relPath := range journal.Writes {
+		stagedPath := filepath.Join(stagingDirPath, relPath)
+		if _, err := os.Stat(stagedPath); os.IsNotExist(err) {
+			// Already applied
+			continue
+		}
+
+		path := filepath.Join(projectPath, relPath)
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		if err = os.Rename(stagedPath, path); err != nil {
+			return console.Error("Failed to write file \"%s\": %v", relPath, err)
+		}
+	}
+
+	for _, relPath := range journal.Deletes {
+		err = os.Remove(filepath.Join(projectPath, relPath))
+		if err != nil && !os.IsNotExist(err) {
+			return console.Error("Failed to delete file \"%s\": %v", relPath, err)
+		}
+	}
+
+	if journal.CommitIndex != 0 {
+		projectConfig, err := GetProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		projectConfig.CurrentCommitIndex = journal.CommitIndex
+		if _, err = SaveProjectConfig(projectPath, projectConfig); err != nil {
+			return err
+		}
+	}
+
+	if err = os.Remove(journalPath); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(stagingDirPath)
+}
+
+// Discard staged files and the journal, leaving the working copy untouched.
+func rollBackWorkingCopyUpdate(journalPath string, stagingDirPath string) error {
+	if err := os.RemoveAll(stagingDirPath); err != nil {
+		return err
+	}
+
+	err := os.Remove(journalPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Returns the path to the directory new file contents are staged in. Doesn't create it.
+func getStagingDirPath() (string, error) {
+	dataDirPath, err := GetProjectDataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDirPath, constants.StagingDirName), nil
+}
+
+// Returns the path to the working copy journal file.
+func getJournalPath() (string, error) {
+	dataDirPath, err := GetProjectDataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDirPath, constants.JournalFileName), nil
+}
+
+// Write journal to disk atomically.
+func writeJournal(journalPath string, journal models.WorkingCopyJournal) error {
+	journalJson, err := json.Marshal(journal)
+	if err != nil {
+		return err
+	}
+
+	return system.WriteFileAtomic(journalPath, journalJson, 0644)
+}
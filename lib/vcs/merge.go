@@ -0,0 +1,53 @@
+package vcs
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/decentvcs/cli/config"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/xyproto/binary"
+)
+
+// Returns true if the file can be merged line by line, i.e. it's a text file that isn't too large
+// to diff.
+func IsMergeable(path string) (bool, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+
+	if fileInfo.Size() > config.I.VCS.MaxFileSizeForDiff {
+		return false, nil
+	}
+
+	isBinary, err := binary.File(path)
+	if err != nil {
+		return false, err
+	}
+
+	return !isBinary, nil
+}
+
+// Three-way merge the changes between `basePath` and `otherPath` into `localPath` using
+// `git merge-file`.
+//
+// Conflicting hunks are left in the local file as conflict markers.
+//
+// Returns true if the merge produced conflicts.
+func MergeTextFile(localPath string, basePath string, otherPath string, otherLabel string) (bool, error) {
+	cmd := exec.Command("git", "merge-file", "-L", "local", "-L", "base", "-L", otherLabel, localPath, basePath, otherPath)
+	err := cmd.Run()
+	if err == nil {
+		return false, nil
+	}
+
+	// Exit code is the amount of conflicts (truncated to 127), or negative on error
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() <= 127 {
+		return true, nil
+	}
+
+	return false, console.Error("Failed to merge file \"%s\": %v", localPath, err)
+}
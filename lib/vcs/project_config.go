@@ -9,6 +9,7 @@ import (
 
 	"github.com/decentvcs/cli/constants"
 	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/system"
 	"github.com/decentvcs/cli/models"
 	"github.com/go-playground/validator/v10"
 	"gopkg.in/yaml.v3"
@@ -46,6 +47,25 @@ func GetProjectConfigPath() (string, error) {
 	return configPath, nil
 }
 
+// Get the path to the project's local data directory, which is used for storing data internal to
+// the CLI (e.g. recovery snapshots). Optional subdirectories are appended to the path.
+//
+// The directory is created if it doesn't exist.
+func GetProjectDataDir(subdirs ...string) (string, error) {
+	projectConfigPath, err := GetProjectConfigPath()
+	if err != nil {
+		return "", err
+	}
+
+	dataDirPath := filepath.Join(append([]string{filepath.Dir(projectConfigPath), constants.ProjectDataDirName}, subdirs...)...)
+	err = os.MkdirAll(dataDirPath, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	return dataDirPath, nil
+}
+
 // Get project config from file in current directory.
 func GetProjectConfig() (models.ProjectConfig, error) {
 	// Get project config file path
@@ -104,7 +124,7 @@ func SaveProjectConfig(path string, c models.ProjectConfig) (models.ProjectConfi
 		return models.ProjectConfig{}, err
 	}
 
-	err = os.WriteFile(configPath, newConfigBytes, os.ModePerm)
+	err = system.WriteFileAtomic(configPath, newConfigBytes, os.ModePerm)
 	return newConfig, err
 }
 
@@ -147,5 +167,8 @@ func MergeProjectConfigs(oldData models.ProjectConfig, newData models.ProjectCon
 		merged.CurrentCommitIndex = newData.CurrentCommitIndex
 	}
 
+	// Can't tell an unset value apart from false, so it's always overwritten
+	merged.Detached = newData.Detached
+
 	return merged
 }
@@ -0,0 +1,41 @@
+package vcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/decentvcs/cli/config"
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/httpvalidation"
+	"github.com/decentvcs/cli/models"
+)
+
+// Get the project of the working copy.
+func GetProject(projectConfig models.ProjectConfig) (models.Project, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s", config.I.VCS.ServerHost, projectConfig.ProjectSlug)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return models.Project{}, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return models.Project{}, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return models.Project{}, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var project models.Project
+	err = json.NewDecoder(res.Body).Decode(&project)
+	if err != nil {
+		return models.Project{}, console.Error("Failed to parse project: %v", err)
+	}
+
+	return project, nil
+}
@@ -0,0 +1,262 @@
+package vcs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/decentvcs/cli/config"
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/system"
+	"github.com/decentvcs/cli/models"
+)
+
+const recoveryManifestFileName = "snapshot.json"
+const recoveryFilesDirName = "files"
+
+// Back up local files before a destructive operation replaces or deletes them.
+//
+// Files are copied into a new timestamped snapshot in the project's recovery directory, which can be
+// restored later with `dvcs recover`. Files that don't exist locally, or whose contents match
+// `committedFiles` (and can therefore be downloaded again), are skipped.
+//
+// Returns the created snapshot, which has no files if nothing needed to be backed up.
+func BackupFiles(projectConfig models.ProjectConfig, operation string, paths []string, committedFiles map[string]models.FileData) (models.RecoverySnapshot, error) {
+	projectConfigPath, err := GetProjectConfigPath()
+	if err != nil {
+		return models.RecoverySnapshot{}, err
+	}
+
+	projectPath := filepath.Dir(projectConfigPath)
+
+	now := time.Now()
+	snapshot := models.RecoverySnapshot{
+		ID:          now.Format("20060102-150405.000"),
+		CreatedAt:   now,
+		Operation:   operation,
+		CommitIndex: projectConfig.CurrentCommitIndex,
+		Files:       []string{},
+	}
+
+	// Determine which files hold content that can't be recovered from remote
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return models.RecoverySnapshot{}, err
+		}
+
+		relPath, err := filepath.Rel(projectPath, absPath)
+		if err != nil {
+			return models.RecoverySnapshot{}, err
+		}
+
+		if _, err := os.Stat(absPath); os.IsNotExist(err) {
+			continue
+		}
+
+		if fileData, ok := committedFiles[relPath]; ok {
+			hash, err := GetFileHash(absPath)
+			if err != nil {
+				return models.RecoverySnapshot{}, err
+			}
+			if hash == fileData.Hash {
+				continue
+			}
+		}
+
+		snapshot.Files = append(snapshot.Files, relPath)
+	}
+
+	if len(snapshot.Files) == 0 {
+		return snapshot, nil
+	}
+
+	snapshotDirPath, err := GetProjectDataDir(constants.RecoveryDirName, snapshot.ID)
+	if err != nil {
+		return models.RecoverySnapshot{}, err
+	}
+
+	// Copy files into snapshot
+	console.Verbose("Backing up %d files to \"%s\"...", len(snapshot.Files), snapshotDirPath)
+	for _, relPath := range snapshot.Files {
+		err = system.CopyFile(filepath.Join(projectPath, relPath), filepath.Join(snapshotDirPath, recoveryFilesDirName, relPath))
+		if err != nil {
+			return models.RecoverySnapshot{}, console.Error("Failed to back up file \"%s\": %v", relPath, err)
+		}
+	}
+
+	// Write manifest last so incomplete snapshots are never listed
+	manifestJson, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return models.RecoverySnapshot{}, err
+	}
+
+	err = os.WriteFile(filepath.Join(snapshotDirPath, recoveryManifestFileName), manifestJson, 0644)
+	if err != nil {
+		return models.RecoverySnapshot{}, err
+	}
+
+	console.Info("Backed up %d local files (recover with `dvcs recover restore %s`)", len(snapshot.Files), snapshot.ID)
+
+	if err = pruneRecoverySnapshots(); err != nil {
+		console.Warning("Failed to delete old recovery snapshots: %v", err)
+	}
+
+	return snapshot, nil
+}
+
+// List all recovery snapshots for the current project, newest first.
+func ListRecoverySnapshots() ([]models.RecoverySnapshot, error) {
+	recoveryDirPath, err := GetProjectDataDir(constants.RecoveryDirName)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(recoveryDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []models.RecoverySnapshot{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		snapshot, err := GetRecoverySnapshot(entry.Name())
+		if err != nil {
+			// Incomplete snapshot, skip it
+			console.Verbose("Skipping recovery snapshot \"%s\": %v", entry.Name(), err)
+			continue
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+// Get a recovery snapshot by ID.
+func GetRecoverySnapshot(id string) (models.RecoverySnapshot, error) {
+	// IDs are directory names inside the recovery directory
+	if id == "" || id == "." || strings.Contains(id, "..") || strings.ContainsAny(id, `/\`) {
+		return models.RecoverySnapshot{}, console.Error("Invalid recovery snapshot ID \"%s\"", id)
+	}
+
+	recoveryDirPath, err := GetProjectDataDir(constants.RecoveryDirName)
+	if err != nil {
+		return models.RecoverySnapshot{}, err
+	}
+
+	manifestBytes, err := os.ReadFile(filepath.Join(recoveryDirPath, id, recoveryManifestFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return models.RecoverySnapshot{}, console.Error("Recovery snapshot \"%s\" not found", id)
+		}
+		return models.RecoverySnapshot{}, err
+	}
+
+	var snapshot models.RecoverySnapshot
+	err = json.Unmarshal(manifestBytes, &snapshot)
+	if err != nil {
+		return models.RecoverySnapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+// Restore files from a recovery snapshot into the working copy.
+//
+// @param paths - Relative fs paths of the files to restore. If empty, all files are restored.
+func RestoreRecoverySnapshot(snapshot models.RecoverySnapshot, paths []string) error {
+	projectConfigPath, err := GetProjectConfigPath()
+	if err != nil {
+		return err
+	}
+
+	projectPath := filepath.Dir(projectConfigPath)
+
+	snapshotDirPath, err := GetProjectDataDir(constants.RecoveryDirName, snapshot.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		paths = snapshot.Files
+	}
+
+	if err = ValidateRecoveryPaths(paths); err != nil {
+		return err
+	}
+
+	for _, relPath := range paths {
+		err = system.CopyFile(filepath.Join(snapshotDirPath, recoveryFilesDirName, relPath), filepath.Join(projectPath, relPath))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return console.Error("File \"%s\" is not in recovery snapshot %s", relPath, snapshot.ID)
+			}
+			return console.Error("Failed to restore file \"%s\": %v", relPath, err)
+		}
+	}
+
+	return nil
+}
+
+// Make sure paths to restore from a recovery snapshot (relative to the project root) are inside
+// the project.
+func ValidateRecoveryPaths(paths []string) error {
+	projectConfigPath, err := GetProjectConfigPath()
+	if err != nil {
+		return err
+	}
+
+	projectPath := filepath.Dir(projectConfigPath)
+	for _, path := range paths {
+		relPath, err := filepath.Rel(projectPath, filepath.Join(projectPath, path))
+		if err != nil {
+			return err
+		}
+
+		if relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			return console.Error("Path \"%s\" is outside of the project", path)
+		}
+	}
+
+	return nil
+}
+
+// Delete recovery snapshots exceeding the configured retention limits.
+func pruneRecoverySnapshots() error {
+	snapshots, err := ListRecoverySnapshots()
+	if err != nil {
+		return err
+	}
+
+	recoveryDirPath, err := GetProjectDataDir(constants.RecoveryDirName)
+	if err != nil {
+		return err
+	}
+
+	maxAge := time.Duration(config.I.VCS.Recovery.RetentionDays) * 24 * time.Hour
+	for i, snapshot := range snapshots {
+		if i < config.I.VCS.Recovery.MaxSnapshots && time.Since(snapshot.CreatedAt) < maxAge {
+			continue
+		}
+
+		console.Verbose("Deleting recovery snapshot %s...", snapshot.ID)
+		err = os.RemoveAll(filepath.Join(recoveryDirPath, snapshot.ID))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
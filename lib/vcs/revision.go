@@ -0,0 +1,154 @@
+package vcs
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/util"
+	"github.com/decentvcs/cli/models"
+)
+
+// Matches ancestor suffixes at the end of a revision, e.g. "~3" or "~~".
+var revisionAncestorsRegex = regexp.MustCompile(`(~\d*)+$`)
+
+// Matches a branch (optional) followed by a date, e.g. "main@{2022-10-01}".
+var revisionDateRegex = regexp.MustCompile(`^(.*)@\{(.+)\}$`)
+
+// Matches a branch followed by a commit index, e.g. "feature@12".
+var revisionBranchIndexRegex = regexp.MustCompile(`^(.+)@(\d+)$`)
+
+// Resolve a revision expression to a commit.
+//
+// Supported expressions:
+//
+// - `42`: Commit with index 42
+//
+// - `head`: Commit the working copy is on
+//
+// - `v1.2`: Commit tagged "v1.2"
+//
+// - `main`: Latest commit on branch "main"
+//
+// - `feature@12`: Latest commit on branch "feature" with an index of 12 or lower
+//
+// - `@{2022-10-01}`, `main@{2022-10-01}`: Latest commit on the current (or specified) branch made on
+// or before a date (YYYY-MM-DD or RFC 3339)
+//
+// Any expression can be followed by `~N` to get its Nth ancestor (`~` is the same as `~1`), e.g.
+// `head~3` or `main~2`. Ancestors are found by walking parents, i.e. previous commits on the same
+// branch and then the commits branches were created from, so indices are usually not consecutive.
+func ResolveRevision(projectConfig models.ProjectConfig, revision string) (models.Commit, error) {
+	revision = strings.TrimSpace(revision)
+
+	// Split ancestor suffixes from base revision
+	ancestors := 0
+	if suffix := revisionAncestorsRegex.FindString(revision); suffix != "" {
+		revision = strings.TrimSuffix(revision, suffix)
+		for _, part := range strings.Split(suffix, "~")[1:] {
+			n := 1
+			if part != "" {
+				n, _ = strconv.Atoi(part)
+			}
+			ancestors += n
+		}
+	}
+
+	if revision == "" {
+		return models.Commit{}, console.Error("Invalid revision; expected a commit index, tag, branch, or \"head\"")
+	}
+
+	commit, err := resolveBaseRevision(projectConfig, revision)
+	if err != nil {
+		return models.Commit{}, err
+	}
+
+	// Walk back to ancestor
+	for i := 0; i < ancestors; i++ {
+		parent, err := GetParentCommit(projectConfig, commit)
+		if err != nil {
+			return models.Commit{}, err
+		}
+		if parent.Index == 0 {
+			return models.Commit{}, console.Error("Revision \"%s\" only has %d ancestors", revision, i)
+		}
+		commit = parent
+	}
+
+	return commit, nil
+}
+
+// Resolve a revision without ancestor suffixes to a commit.
+func resolveBaseRevision(projectConfig models.ProjectConfig, revision string) (models.Commit, error) {
+	// Commit index
+	if index, err := strconv.Atoi(revision); err == nil {
+		return GetCommit(projectConfig, index)
+	}
+
+	// Working copy commit
+	if strings.EqualFold(revision, "head") {
+		return GetCommit(projectConfig, projectConfig.CurrentCommitIndex)
+	}
+
+	// Branch at date
+	if match := revisionDateRegex.FindStringSubmatch(revision); match != nil {
+		branchName := match[1]
+		if branchName == "" {
+			branchName = projectConfig.CurrentBranchName
+		}
+
+		date, err := util.ParseDate(match[2], true)
+		if err != nil {
+			return models.Commit{}, err
+		}
+
+		commits, err := GetBranchCommits(projectConfig, branchName)
+		if err != nil {
+			return models.Commit{}, err
+		}
+
+		// Commits are sorted by index, which is also the order they were made in
+		for i := len(commits) - 1; i >= 0; i-- {
+			if !commits[i].CreatedAt.After(date) {
+				return commits[i], nil
+			}
+		}
+
+		return models.Commit{}, console.Error("Branch \"%s\" has no commits made on or before %s", branchName, match[2])
+	}
+
+	// Branch at commit index
+	if match := revisionBranchIndexRegex.FindStringSubmatch(revision); match != nil {
+		index, _ := strconv.Atoi(match[2])
+		commits, err := GetBranchCommits(projectConfig, match[1])
+		if err != nil {
+			return models.Commit{}, err
+		}
+
+		for i := len(commits) - 1; i >= 0; i-- {
+			if commits[i].Index <= index {
+				return commits[i], nil
+			}
+		}
+
+		return models.Commit{}, console.Error("Branch \"%s\" has no commits with an index of %d or lower", match[1], index)
+	}
+
+	// Tagged commit
+	tag, ok, err := FindTag(projectConfig, revision)
+	if err != nil {
+		return models.Commit{}, err
+	}
+	if ok {
+		return GetCommit(projectConfig, tag.CommitIndex)
+	}
+
+	// Latest commit on branch
+	branch, err := GetBranchWithCommit(projectConfig, revision)
+	if err != nil {
+		return models.Commit{}, err
+	}
+
+	return branch.Commit, nil
+}
@@ -0,0 +1,126 @@
+package vcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/decentvcs/cli/config"
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/httpvalidation"
+	"github.com/decentvcs/cli/models"
+)
+
+// Register a new shelf in the project.
+// Files must already be uploaded to storage.
+func CreateShelf(projectConfig models.ProjectConfig, dto models.ShelfCreateDTO) (models.Shelf, error) {
+	bodyJson, err := json.Marshal(dto)
+	if err != nil {
+		return models.Shelf{}, err
+	}
+
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/shelves", config.I.VCS.ServerHost, projectConfig.ProjectSlug)
+	req, err := http.NewRequest("POST", reqUrl, bytes.NewBuffer(bodyJson))
+	if err != nil {
+		return models.Shelf{}, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return models.Shelf{}, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return models.Shelf{}, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var shelf models.Shelf
+	err = json.NewDecoder(res.Body).Decode(&shelf)
+	if err != nil {
+		return models.Shelf{}, console.Error("Failed to parse shelf: %v", err)
+	}
+
+	return shelf, nil
+}
+
+// Get all shelves in the project.
+func GetShelves(projectConfig models.ProjectConfig) ([]models.Shelf, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/shelves", config.I.VCS.ServerHost, projectConfig.ProjectSlug)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var shelves []models.Shelf
+	err = json.NewDecoder(res.Body).Decode(&shelves)
+	if err != nil {
+		return nil, console.Error("Failed to parse shelves: %v", err)
+	}
+
+	return shelves, nil
+}
+
+// Get a shelf by name.
+func GetShelf(projectConfig models.ProjectConfig, name string) (models.Shelf, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/shelves/%s", config.I.VCS.ServerHost, projectConfig.ProjectSlug, name)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return models.Shelf{}, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return models.Shelf{}, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return models.Shelf{}, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var shelf models.Shelf
+	err = json.NewDecoder(res.Body).Decode(&shelf)
+	if err != nil {
+		return models.Shelf{}, console.Error("Failed to parse shelf: %v", err)
+	}
+
+	return shelf, nil
+}
+
+// Delete a shelf by name.
+func DeleteShelf(projectConfig models.ProjectConfig, name string) error {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/shelves/%s", config.I.VCS.ServerHost, projectConfig.ProjectSlug, name)
+	req, err := http.NewRequest("DELETE", reqUrl, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
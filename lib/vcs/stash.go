@@ -0,0 +1,194 @@
+package vcs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/system"
+	"github.com/decentvcs/cli/models"
+)
+
+const stashManifestFileName = "stash.json"
+const stashFilesDirName = "files"
+
+// Save local changes into a new stash and reset them in the working copy.
+//
+// @param message - Description of the changes (optional)
+//
+// @param paths - Relative fs paths of files or directories to stash. If empty, all changes are
+// stashed.
+func StashChanges(projectConfig models.ProjectConfig, message string, paths []string) (models.Stash, error) {
+	projectConfigPath, err := GetProjectConfigPath()
+	if err != nil {
+		return models.Stash{}, err
+	}
+
+	projectPath := filepath.Dir(projectConfigPath)
+
+	currentCommit, err := GetCommit(projectConfig, projectConfig.CurrentCommitIndex)
+	if err != nil {
+		return models.Stash{}, err
+	}
+
+	fc, err := DetectFileChanges(currentCommit.Files, WithQuiet(), WithPaths(paths))
+	if err != nil {
+		return models.Stash{}, err
+	}
+
+	if len(fc.CreatedFilePaths) == 0 && len(fc.ModifiedFilePaths) == 0 && len(fc.DeletedFilePaths) == 0 {
+		return models.Stash{}, console.Error("No local changes to stash")
+	}
+
+	now := time.Now()
+	stash := models.Stash{
+		ID:            now.Format("20060102-150405.000"),
+		CreatedAt:     now,
+		Message:       message,
+		BranchName:    projectConfig.CurrentBranchName,
+		CommitIndex:   projectConfig.CurrentCommitIndex,
+		CreatedFiles:  fc.CreatedFilePaths,
+		ModifiedFiles: fc.ModifiedFilePaths,
+		DeletedFiles:  fc.DeletedFilePaths,
+		BaseFiles:     make(map[string]models.FileData),
+	}
+
+	stashDirPath, err := GetProjectDataDir(constants.StashDirName, stash.ID)
+	if err != nil {
+		return models.Stash{}, err
+	}
+
+	// Save contents of created and modified files
+	for _, relPath := range append(append([]string{}, stash.CreatedFiles...), stash.ModifiedFiles...) {
+		err = system.CopyFile(filepath.Join(projectPath, relPath), filepath.Join(stashDirPath, stashFilesDirName, relPath))
+		if err != nil {
+			os.RemoveAll(stashDirPath)
+			return models.Stash{}, console.Error("Failed to stash file \"%s\": %v", relPath, err)
+		}
+	}
+
+	// Reset modified and deleted files to their committed versions, and delete created files
+	downloads := make(map[string]string)
+	for _, relPath := range append(append([]string{}, stash.ModifiedFiles...), stash.DeletedFiles...) {
+		stash.BaseFiles[relPath] = currentCommit.Files[relPath]
+		downloads[relPath] = currentCommit.Files[relPath].Hash
+	}
+
+	// Write manifest before touching the working copy so the changes can't be lost
+	manifestJson, err := json.MarshalIndent(stash, "", "  ")
+	if err != nil {
+		return models.Stash{}, err
+	}
+
+	err = system.WriteFileAtomic(filepath.Join(stashDirPath, stashManifestFileName), manifestJson, 0644)
+	if err != nil {
+		return models.Stash{}, err
+	}
+
+	err = ApplyWorkingCopyUpdate(projectConfig, WorkingCopyUpdate{
+		Operation: "stash",
+		Downloads: downloads,
+		Deletes:   stash.CreatedFiles,
+	})
+	if err != nil {
+		return models.Stash{}, err
+	}
+
+	return stash, nil
+}
+
+// List all stashes for the current project, newest first.
+func ListStashes() ([]models.Stash, error) {
+	stashesDirPath, err := GetProjectDataDir(constants.StashDirName)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(stashesDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stashes := []models.Stash{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		manifestBytes, err := os.ReadFile(filepath.Join(stashesDirPath, entry.Name(), stashManifestFileName))
+		if err != nil {
+			// Incomplete stash, skip it
+			console.Verbose("Skipping stash \"%s\": %v", entry.Name(), err)
+			continue
+		}
+
+		var stash models.Stash
+		if err = json.Unmarshal(manifestBytes, &stash); err != nil {
+			return nil, err
+		}
+
+		stashes = append(stashes, stash)
+	}
+
+	sort.Slice(stashes, func(i, j int) bool {
+		return stashes[i].CreatedAt.After(stashes[j].CreatedAt)
+	})
+
+	return stashes, nil
+}
+
+// Get a stash by its position in the list of stashes, where 0 is the newest.
+func GetStash(index int) (models.Stash, error) {
+	stashes, err := ListStashes()
+	if err != nil {
+		return models.Stash{}, err
+	}
+
+	if index < 0 || index >= len(stashes) {
+		return models.Stash{}, console.Error("Stash %d not found (run `dvcs stash list` to list stashes)", index)
+	}
+
+	return stashes[index], nil
+}
+
+// Reapply stashed changes to the working copy.
+//
+// Files that were changed again since they were stashed are merged if they're text files, or
+// reported as conflicts otherwise.
+func ApplyStash(projectConfig models.ProjectConfig, stash models.Stash) (ApplyFileChangesResult, error) {
+	stashDirPath, err := GetProjectDataDir(constants.StashDirName, stash.ID)
+	if err != nil {
+		return ApplyFileChangesResult{}, err
+	}
+
+	// Stashed versions of files, where deleted files are absent
+	target := make(map[string]models.FileData)
+	targetFiles := make(map[string]string)
+	for _, relPath := range append(append([]string{}, stash.CreatedFiles...), stash.ModifiedFiles...) {
+		path := filepath.Join(stashDirPath, stashFilesDirName, relPath)
+		hash, err := GetFileHash(path)
+		if err != nil {
+			return ApplyFileChangesResult{}, err
+		}
+
+		target[relPath] = models.FileData{Hash: hash}
+		targetFiles[relPath] = path
+	}
+
+	paths := append(append(append([]string{}, stash.CreatedFiles...), stash.ModifiedFiles...), stash.DeletedFiles...)
+	return ApplyFileChanges(projectConfig, "stash", "stash", stash.BaseFiles, target, paths, WithTargetFiles(targetFiles))
+}
+
+// Delete a stash.
+func DropStash(stash models.Stash) error {
+	stashesDirPath, err := GetProjectDataDir(constants.StashDirName)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(filepath.Join(stashesDirPath, stash.ID))
+}
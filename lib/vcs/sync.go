@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/decentvcs/cli/config"
@@ -13,27 +14,44 @@ import (
 	"github.com/decentvcs/cli/lib/console"
 	"github.com/decentvcs/cli/lib/httpvalidation"
 	"github.com/decentvcs/cli/lib/storage"
+	"github.com/decentvcs/cli/lib/system"
 	"github.com/decentvcs/cli/models"
+	"github.com/samber/lo"
 	"golang.org/x/exp/maps"
 )
 
+// Result of `vcs.SyncToCommit()`
+type SyncResult struct {
+	// Whether the working copy was synced. False if the sync was aborted or not needed.
+	Synced bool
+	// Paths of files that were merged with conflicts, which are left as conflict markers.
+	Conflicts []string
+}
+
 // Sync to a specific commit.
-func SyncToCommit(projectConfig models.ProjectConfig, commitIndex int, confirm bool) error {
+//
+// Local changes are detected by comparing local hashes against the current commit. Files changed
+// only remotely are overwritten, text files changed on both sides are merged, and the user is
+// prompted for any other file changed on both sides. Local changes are never discarded without
+// confirmation.
+//
+// If `commitIndex` is 0, syncs to the latest commit on the current branch.
+func SyncToCommit(projectConfig models.ProjectConfig, commitIndex int, confirm bool) (SyncResult, error) {
 	console.Verbose("Getting current commit...")
 	httpClient := &http.Client{}
 
 	// Get current commit
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s/projects/%s/commits/%d", config.I.VCS.ServerHost, projectConfig.ProjectSlug, projectConfig.CurrentCommitIndex), nil)
 	if err != nil {
-		return err
+		return SyncResult{}, err
 	}
 	req.Header.Add(constants.SessionTokenHeader, config.I.Auth.SessionToken)
 	commitRes, err := httpClient.Do(req)
 	if err != nil {
-		return err
+		return SyncResult{}, err
 	}
 	if err = httpvalidation.ValidateResponse(commitRes); err != nil {
-		return err
+		return SyncResult{}, err
 	}
 	defer commitRes.Body.Close()
 
@@ -41,7 +59,7 @@ func SyncToCommit(projectConfig models.ProjectConfig, commitIndex int, confirm b
 	var currentCommit models.Commit
 	err = json.NewDecoder(commitRes.Body).Decode(&currentCommit)
 	if err != nil {
-		return console.Error(constants.ErrInternal)
+		return SyncResult{}, console.Error(constants.ErrInternal)
 	}
 
 	// Get specified commit ID from args; default to latest commit
@@ -53,15 +71,15 @@ func SyncToCommit(projectConfig models.ProjectConfig, commitIndex int, confirm b
 		// Get current branch with latest commit
 		req, err = http.NewRequest("GET", fmt.Sprintf("%s/projects/%s/branches/%s?join_commit=true", config.I.VCS.ServerHost, projectConfig.ProjectSlug, projectConfig.CurrentBranchName), nil)
 		if err != nil {
-			return err
+			return SyncResult{}, err
 		}
 		req.Header.Add(constants.SessionTokenHeader, config.I.Auth.SessionToken)
 		res, err := httpClient.Do(req)
 		if err != nil {
-			return err
+			return SyncResult{}, err
 		}
 		if err = httpvalidation.ValidateResponse(res); err != nil {
-			return err
+			return SyncResult{}, err
 		}
 		defer res.Body.Close()
 
@@ -69,7 +87,7 @@ func SyncToCommit(projectConfig models.ProjectConfig, commitIndex int, confirm b
 		var branchwc models.BranchWithCommit
 		err = json.NewDecoder(res.Body).Decode(&branchwc)
 		if err != nil {
-			return console.Error(constants.ErrInternal)
+			return SyncResult{}, console.Error(constants.ErrInternal)
 		}
 
 		toCommit = branchwc.Commit
@@ -78,150 +96,303 @@ func SyncToCommit(projectConfig models.ProjectConfig, commitIndex int, confirm b
 
 		// Validate commit index
 		if commitIndex <= 0 {
-			return console.Error("Invalid commit index. Must be a positive integer.")
+			return SyncResult{}, console.Error("Invalid commit index. Must be a positive integer.")
 		}
 
 		// Get user-specified commit
 		req, err = http.NewRequest("GET", fmt.Sprintf("%s/projects/%s/commits/%d", config.I.VCS.ServerHost, projectConfig.ProjectSlug, commitIndex), nil)
 		if err != nil {
-			return err
+			return SyncResult{}, err
 		}
 		req.Header.Add(constants.SessionTokenHeader, config.I.Auth.SessionToken)
 		res, err := httpClient.Do(req)
 		if err != nil {
-			return err
+			return SyncResult{}, err
 		}
 		if err = httpvalidation.ValidateResponse(res); err != nil {
-			return err
+			return SyncResult{}, err
 		}
 		defer res.Body.Close()
 
 		// Parse commit
 		err = json.NewDecoder(res.Body).Decode(&toCommit)
 		if err != nil {
-			return console.Error(constants.ErrInternal)
+			return SyncResult{}, console.Error(constants.ErrInternal)
 		}
 	}
 
 	// Return if commit is the same as current commit
 	if toCommit.Index == projectConfig.CurrentCommitIndex {
 		console.Info("You are already on this commit")
-		return nil
+		return SyncResult{}, nil
 	}
 
-	// Get keys for new files by comparing hash maps
 	console.Verbose("\n\"to\" commit hash map:\n%v", toCommit.Files)
 	console.Verbose("\nCurrent commit hash map:\n%v\n", currentCommit.Files)
 
+	// Calculate local hash map to detect local changes
+	localHashMap, err := CalculateHashes(".")
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	// Classify every known path by which side changed it since the current commit
+	allPaths := lo.Uniq(append(append(maps.Keys(currentCommit.Files), maps.Keys(toCommit.Files)...), maps.Keys(localHashMap)...))
+	sort.Strings(allPaths)
+
 	downloadMap := make(map[string]string)
-	filesToOverride := []string{}
-	for toFilePath, toFileData := range toCommit.Files {
-		if curFileData, ok := currentCommit.Files[toFilePath]; ok {
-			// File exists in both commits
-			//
-			// If file is modified, add to download map
-			if toFileData.Hash != curFileData.Hash {
-				downloadMap[toFilePath] = toFileData.Hash
+	filesToDelete := []string{}
+	mergeMap := make(map[string]string)
+	unmergeable := []string{}
+	localOnlyCount := 0
+
+	for _, path := range allPaths {
+		baseHash := currentCommit.Files[path].Hash
+		toHash := toCommit.Files[path].Hash
+		localHash := localHashMap[path]
+
+		remoteChanged := baseHash != toHash
+		localChanged := baseHash != localHash
+
+		if !remoteChanged {
+			if localChanged {
+				localOnlyCount++
+			}
+			continue
+		}
+
+		if !localChanged {
+			// Remote-only change
+			if toHash == "" {
+				filesToDelete = append(filesToDelete, path)
+			} else {
+				downloadMap[path] = toHash
 			}
 			continue
 		}
 
-		// File is new from last commit
-		//
-		// Add to override list if it exists in local changes
-		if _, err := os.Stat(toFilePath); err == nil {
-			filesToOverride = append(filesToOverride, toFilePath)
+		// Changed both locally and remotely
+		if localHash == toHash {
+			continue
+		}
+
+		if localHash != "" && toHash != "" {
+			mergeable, err := IsMergeable(path)
+			if err != nil {
+				return SyncResult{}, err
+			}
+
+			if mergeable {
+				mergeMap[path] = toHash
+				continue
+			}
 		}
 
-		// Add new file to download map
-		downloadMap[toFilePath] = toFileData.Hash
+		unmergeable = append(unmergeable, path)
 	}
 
-	confirmed := false
+	console.Verbose("\nFiles to download: %v", maps.Keys(downloadMap))
+	console.Verbose("\nFiles to delete: %v", filesToDelete)
+	console.Verbose("\nFiles to merge: %v", maps.Keys(mergeMap))
 
-	// Warn user about local file overrides and prompt to continue
-	if len(filesToOverride) > 0 && confirm {
-		console.Warning("The following files are not mergeable and will be overridden by remote changes:")
+	if len(downloadMap) == 0 && len(filesToDelete) == 0 && len(mergeMap) == 0 && len(unmergeable) == 0 {
+		// Nothing to apply, but the working copy is now on the commit
+		projectConfig.CurrentCommitIndex = toCommit.Index
+		projectConfigPath, err := GetProjectConfigPath()
+		if err != nil {
+			return SyncResult{}, err
+		}
 
-		for _, key := range filesToOverride {
-			console.Warning("  %s", key)
+		if _, err = SaveProjectConfig(filepath.Dir(projectConfigPath), projectConfig); err != nil {
+			return SyncResult{}, err
 		}
 
-		console.Warning("Continue? (y/n)")
-		var answer string
-		fmt.Scanln(&answer)
+		console.Info("Your local files already match commit #%d", toCommit.Index)
+		console.Info("Synced to commit #%d", toCommit.Index)
 
-		if strings.ToLower(answer) != "y" {
-			console.Info("Aborted")
-			return nil
+		if err = updateDetachedState(toCommit); err != nil {
+			return SyncResult{}, err
 		}
 
-		confirmed = true
+		return SyncResult{Synced: true, Conflicts: []string{}}, nil
 	}
 
-	// Get keys for deleted files by comparing hash maps
-	filesToDelete := []string{}
-	for filePath, fileData := range currentCommit.Files {
-		if _, ok := toCommit.Files[filePath]; !ok {
-			// File is deleted from last commit; calculate local file hash to check if changed
-			lclHash, err := GetFileHash(filePath)
-			if err != nil {
-				return err
-			}
-			if lclHash == fileData.Hash {
-				// File is unchanged from current commit remote, add to list of files to delete
-				filesToDelete = append(filesToDelete, filePath)
-			}
+	// Print summary
+	console.Info("Syncing to commit #%d (\"%s\"):", toCommit.Index, toCommit.Message)
+	console.Info("  %d remote changes will be applied", len(downloadMap)+len(filesToDelete))
+	if localOnlyCount > 0 {
+		console.Info("  %d local changes will be kept", localOnlyCount)
+	}
+	if len(mergeMap) > 0 {
+		console.Info("  %d files changed both locally and remotely will be merged:", len(mergeMap))
+		for _, path := range maps.Keys(mergeMap) {
+			console.Info("    %s", path)
 		}
 	}
 
-	console.Verbose("\nFiles to delete: %v", filesToDelete)
+	// Files changed on both sides that can't be merged are never overwritten without confirmation
+	if len(unmergeable) > 0 {
+		console.Warning("The following files were changed both locally and remotely and cannot be merged:")
+		for _, path := range unmergeable {
+			console.Warning("  %s", path)
+		}
 
-	if len(maps.Keys(downloadMap)) == 0 && len(filesToDelete) == 0 {
-		console.Info("Your local changes are equivalent to the commit you are syncing to.")
-		console.Info("Aborted")
-		return nil
-	}
+		if !confirm {
+			return SyncResult{}, console.Error("Sync aborted to prevent losing local changes. Run without --yes to choose which versions to keep.")
+		}
 
-	// Prompt user to confirm sync
-	if confirm && !confirmed {
+		for _, path := range unmergeable {
+			console.Warning("\"%s\": keep [l]ocal version, use [r]emote version, or [a]bort?", path)
+			var answer string
+			fmt.Scanln(&answer)
+
+			switch strings.ToLower(answer) {
+			case "l":
+				continue
+			case "r":
+				if toHash := toCommit.Files[path].Hash; toHash == "" {
+					filesToDelete = append(filesToDelete, path)
+				} else {
+					downloadMap[path] = toHash
+				}
+			default:
+				console.Info("Aborted")
+				return SyncResult{}, nil
+			}
+		}
+	} else if confirm {
+		// Prompt user to confirm sync
 		console.Warning("Sync to commit #%d (\"%s\")? (y/n)", toCommit.Index, toCommit.Message)
 		var answer string
 		fmt.Scanln(&answer)
 
 		if strings.ToLower(answer) != "y" {
 			console.Info("Aborted")
-			return nil
+			return SyncResult{}, nil
 		}
 	}
 
-	if len(maps.Keys(downloadMap)) > 0 {
-		err := storage.DownloadMany(projectConfig, ".", downloadMap)
-		if err != nil {
-			return err
+	// Back up local changes that are about to be replaced
+	_, err = BackupFiles(projectConfig, "sync", append(append(maps.Keys(downloadMap), filesToDelete...), maps.Keys(mergeMap)...), currentCommit.Files)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	// Download both versions of mergeable files
+	tempDirPath, err := os.MkdirTemp(system.GetTempDir(), "sync-")
+	if err != nil {
+		return SyncResult{}, err
+	}
+	defer os.RemoveAll(tempDirPath)
+
+	baseDirPath := filepath.Join(tempDirPath, "base")
+	remoteDirPath := filepath.Join(tempDirPath, "remote")
+	mergedDirPath := filepath.Join(tempDirPath, "merged")
+	if len(mergeMap) > 0 {
+		baseMergeMap := make(map[string]string)
+		for path := range mergeMap {
+			if hash := currentCommit.Files[path].Hash; hash != "" {
+				baseMergeMap[path] = hash
+			}
+		}
+
+		if err := storage.DownloadManyCached(projectConfig, remoteDirPath, mergeMap); err != nil {
+			return SyncResult{}, err
+		}
+		if len(baseMergeMap) > 0 {
+			if err := storage.DownloadManyCached(projectConfig, baseDirPath, baseMergeMap); err != nil {
+				return SyncResult{}, err
+			}
 		}
 	}
 
-	// Delete deleted files
-	for _, key := range filesToDelete {
-		err = os.Remove(key)
+	// Merge files changed on both sides into copies of the local files
+	res := SyncResult{Conflicts: []string{}}
+	mergedFiles := make(map[string]string)
+	if len(mergeMap) > 0 {
+		// Files created on both sides are merged against an empty base
+		emptyFilePath := filepath.Join(tempDirPath, "empty")
+		err = os.WriteFile(emptyFilePath, []byte{}, 0644)
 		if err != nil {
-			return console.Error("Failed to delete file %s; %s", key, err)
+			return SyncResult{}, console.Error("Failed to create base file: %s", err)
+		}
+
+		for path := range mergeMap {
+			basePath := filepath.Join(baseDirPath, path)
+			if _, ok := currentCommit.Files[path]; !ok {
+				basePath = emptyFilePath
+			}
+
+			mergedPath := filepath.Join(mergedDirPath, path)
+			if err = system.CopyFile(path, mergedPath); err != nil {
+				return SyncResult{}, err
+			}
+
+			conflicted, err := MergeTextFile(mergedPath, basePath, filepath.Join(remoteDirPath, path), fmt.Sprintf("commit #%d", toCommit.Index))
+			if err != nil {
+				return SyncResult{}, err
+			}
+			if conflicted {
+				res.Conflicts = append(res.Conflicts, path)
+			}
+
+			mergedFiles[path] = mergedPath
 		}
 	}
 
-	// Update current commit ID in project config
-	projectConfig.CurrentCommitIndex = toCommit.Index
-	projectConfigPath, err := GetProjectConfigPath()
+	// Apply changes and update current commit index in project config
+	err = ApplyWorkingCopyUpdate(projectConfig, WorkingCopyUpdate{
+		Operation:   "sync",
+		Downloads:   downloadMap,
+		Files:       mergedFiles,
+		Deletes:     filesToDelete,
+		CommitIndex: toCommit.Index,
+	})
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	res.Synced = true
+	console.Info("Synced to commit #%d", toCommit.Index)
+
+	if err = updateDetachedState(toCommit); err != nil {
+		return SyncResult{}, err
+	}
+
+	if len(res.Conflicts) > 0 {
+		console.Warning("The following files have merge conflicts that must be resolved:")
+		for _, path := range res.Conflicts {
+			console.Warning("  %s", path)
+		}
+	}
+
+	return res, nil
+}
+
+// Record in the project config whether the working copy is detached, i.e. whether the commit it was
+// synced to doesn't belong to the current branch.
+func updateDetachedState(commit models.Commit) error {
+	projectConfig, err := GetProjectConfig()
 	if err != nil {
 		return err
 	}
 
-	if _, err = SaveProjectConfig(filepath.Dir(projectConfigPath), projectConfig); err != nil {
+	branch, err := GetBranch(projectConfig, projectConfig.CurrentBranchName)
+	if err != nil {
 		return err
 	}
 
-	console.Info("Synced to commit #%d", toCommit.Index)
+	projectConfig.Detached = commit.BranchID != branch.ID && commit.ID != branch.CommitID
+	if projectConfig.Detached {
+		console.Warning("Commit #%d is not on branch \"%s\", so you're now in a detached state.", commit.Index, branch.Name)
+		console.Warning("To push changes made here, create a branch for them with `dvcs branch new <name>`.")
+	}
+
+	projectConfigPath, err := GetProjectConfigPath()
+	if err != nil {
+		return err
+	}
 
-	return nil
+	_, err = SaveProjectConfig(filepath.Dir(projectConfigPath), projectConfig)
+	return err
 }
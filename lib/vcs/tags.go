@@ -0,0 +1,130 @@
+package vcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/decentvcs/cli/config"
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/httpvalidation"
+	"github.com/decentvcs/cli/models"
+)
+
+// Create a new tag pointing at a commit.
+func CreateTag(projectConfig models.ProjectConfig, dto models.TagCreateDTO) (models.Tag, error) {
+	bodyJson, err := json.Marshal(dto)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/tags", config.I.VCS.ServerHost, projectConfig.ProjectSlug)
+	req, err := http.NewRequest("POST", reqUrl, bytes.NewBuffer(bodyJson))
+	if err != nil {
+		return models.Tag{}, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return models.Tag{}, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var tag models.Tag
+	err = json.NewDecoder(res.Body).Decode(&tag)
+	if err != nil {
+		return models.Tag{}, console.Error("Failed to parse tag: %v", err)
+	}
+
+	return tag, nil
+}
+
+// Get all tags in the project.
+func GetTags(projectConfig models.ProjectConfig) ([]models.Tag, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/tags", config.I.VCS.ServerHost, projectConfig.ProjectSlug)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var tags []models.Tag
+	err = json.NewDecoder(res.Body).Decode(&tags)
+	if err != nil {
+		return nil, console.Error("Failed to parse tags: %v", err)
+	}
+
+	return tags, nil
+}
+
+// Find a tag by name.
+// Returns false if there's no tag with that name.
+func FindTag(projectConfig models.ProjectConfig, name string) (models.Tag, bool, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/tags/%s", config.I.VCS.ServerHost, projectConfig.ProjectSlug, name)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return models.Tag{}, false, err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return models.Tag{}, false, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return models.Tag{}, false, nil
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return models.Tag{}, false, err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var tag models.Tag
+	err = json.NewDecoder(res.Body).Decode(&tag)
+	if err != nil {
+		return models.Tag{}, false, console.Error("Failed to parse tag: %v", err)
+	}
+
+	return tag, true, nil
+}
+
+// Delete a tag by name.
+func DeleteTag(projectConfig models.ProjectConfig, name string) error {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/projects/%s/tags/%s", config.I.VCS.ServerHost, projectConfig.ProjectSlug, name)
+	req, err := http.NewRequest("DELETE", reqUrl, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package vcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/decentvcs/cli/config"
+	"github.com/decentvcs/cli/constants"
+	"github.com/decentvcs/cli/lib/console"
+	"github.com/decentvcs/cli/lib/httpvalidation"
+	"github.com/decentvcs/cli/models"
+	"github.com/stytchauth/stytch-go/v5/stytch"
+)
+
+// Cache of user IDs to display names, since the same users are often looked up many times.
+var userNameCache = make(map[string]string)
+
+// Get the display name of a user, which is their full name if set, otherwise their email.
+// Returns "system" for an empty user ID (e.g. for commits created by the system).
+func GetUserName(userID string) (string, error) {
+	if userID == "" {
+		return "system", nil
+	}
+
+	if name, ok := userNameCache[userID]; ok {
+		return name, nil
+	}
+
+	// Get Stytch user from server
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/stytch/users/%s", config.I.VCS.ServerHost, userID)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var stytchUser stytch.UsersGetResponse
+	err = json.NewDecoder(res.Body).Decode(&stytchUser)
+	if err != nil {
+		return "", err
+	}
+
+	var name string
+	if stytchUser.Name.FirstName != "" || stytchUser.Name.LastName != "" {
+		// Use name
+		name = stytchUser.Name.FirstName + " " + stytchUser.Name.LastName
+	} else if len(stytchUser.Emails) > 0 {
+		// Use first email
+		name = stytchUser.Emails[0].Email
+	} else {
+		name = userID
+	}
+
+	userNameCache[userID] = name
+	return name, nil
+}
+
+// Get the ID of the authenticated user.
+func GetCurrentUserID() (string, error) {
+	httpClient := http.Client{}
+	reqUrl := fmt.Sprintf("%s/session", config.I.VCS.ServerHost)
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(constants.SessionTokenHeader, config.I.Auth.SessionToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	if err = httpvalidation.ValidateResponse(res); err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	// Parse response
+	var session models.SessionResponse
+	err = json.NewDecoder(res.Body).Decode(&session)
+	if err != nil {
+		return "", console.Error("Failed to parse session: %v", err)
+	}
+
+	return session.UserID, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/decentvcs/cli/cmd"
 	"github.com/decentvcs/cli/config"
+	"github.com/decentvcs/cli/lib/vcs"
 	"github.com/urfave/cli/v2"
 )
 
@@ -13,6 +14,16 @@ func main() {
 	// Initialize config
 	config.InitConfig()
 
+	// Shared by `log` and its `file history` alias
+	fileHistoryFlags := []cli.Flag{
+		&cli.IntFlag{
+			Name:    "limit",
+			Aliases: []string{"l"},
+			Usage:   "Limit number of commits (0 for no limit)",
+			Value:   20,
+		},
+	}
+
 	// Initialize CLI app
 	app := &cli.App{
 		Name:      "dvcs",
@@ -25,6 +36,10 @@ func main() {
 				Email: "josh@decentvcs.com",
 			},
 		},
+		// Complete or roll back any working copy update interrupted by a previous command
+		Before: func(c *cli.Context) error {
+			return vcs.RecoverWorkingCopyUpdate()
+		},
 		Commands: []*cli.Command{
 			{
 				Name:   "login",
@@ -69,6 +84,27 @@ func main() {
 				Aliases: []string{"c"},
 				Action:  cmd.GetChanges,
 			},
+			{
+				Name:      "diff",
+				Usage:     "Print diff of local changes, or between two commits or branches",
+				ArgsUsage: "[from? to?] [paths...?]",
+				Action:    cmd.Diff,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "stat",
+						Usage: "Only print amount of changed lines per file",
+					},
+					&cli.BoolFlag{
+						Name:  "name-status",
+						Usage: "Only print status and path of changed files",
+					},
+					&cli.BoolFlag{
+						Name:    "patch",
+						Aliases: []string{"p"},
+						Usage:   "When comparing commits, download file contents and print text diffs",
+					},
+				},
+			},
 			{
 				Name:      "push",
 				Usage:     "Push local changes to remote",
@@ -88,17 +124,48 @@ func main() {
 						Aliases: []string{"f"},
 						Usage:   "Force push",
 					},
+					&cli.BoolFlag{
+						Name:    "rebase",
+						Aliases: []string{"r"},
+						Usage:   "If behind the remote branch, sync to its latest commit first while keeping local changes",
+					},
 					&cli.StringFlag{
 						Name:    "message",
 						Aliases: []string{"m"},
 						Usage:   "Commit message",
 					},
+					&cli.StringFlag{
+						Name:    "changelist",
+						Aliases: []string{"c"},
+						Usage:   "Only push changes to files in this changelist",
+					},
+					&cli.BoolFlag{
+						Name:  "amend",
+						Usage: "Replace your latest commit with one that also contains local changes (and the new message, if specified)",
+					},
+				},
+			},
+			{
+				Name:   "amend",
+				Usage:  "Replace your latest commit with one that also contains local changes and/or a new message",
+				Action: cmd.Amend,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Skip confirmation",
+					},
+					&cli.StringFlag{
+						Name:    "message",
+						Aliases: []string{"m"},
+						Usage:   "New commit message",
+					},
 				},
 			},
 			{
 				Name:      "sync",
 				Usage:     "Sync to commit, downloading changes from remote",
-				ArgsUsage: "[commit_index?]",
+				ArgsUsage: "[revision?]",
 				Aliases:   []string{"to", "s"},
 				Action:    cmd.Sync,
 				Flags: []cli.Flag{
@@ -123,9 +190,10 @@ func main() {
 				},
 			},
 			{
-				Name:   "revert",
-				Usage:  "Reset all local changes and sync to last commit",
-				Action: cmd.Revert,
+				Name:      "revert",
+				Usage:     "Create a new commit undoing the changes of a commit",
+				ArgsUsage: "[revision]",
+				Action:    cmd.Revert,
 				Flags: []cli.Flag{
 					&cli.BoolFlag{
 						Name:    "yes",
@@ -134,6 +202,346 @@ func main() {
 					},
 				},
 			},
+			{
+				Name:      "restore",
+				Usage:     "Restore individual files from a commit or branch",
+				ArgsUsage: "[paths...]",
+				Action:    cmd.Restore,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "commit",
+						Aliases: []string{"c"},
+						Usage:   "Revision to restore files from (defaults to current commit)",
+					},
+					&cli.StringFlag{
+						Name:    "branch",
+						Aliases: []string{"b"},
+						Usage:   "Branch whose latest commit to restore files from",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Directory to write files to instead of the working copy",
+					},
+				},
+			},
+			{
+				Name:      "cat",
+				Usage:     "Write the contents of a file in a commit to stdout",
+				ArgsUsage: "[revision] [path]",
+				Action:    cmd.Cat,
+			},
+			{
+				Name:      "cherry-pick",
+				Usage:     "Apply the changes of a commit from another branch and push them",
+				ArgsUsage: "[revision]",
+				Action:    cmd.CherryPick,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Skip confirmation",
+					},
+				},
+			},
+			{
+				Name:  "stash",
+				Usage: "Save local changes for later and reset them",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "push",
+						Usage:     "Save local changes into a new stash and reset them",
+						ArgsUsage: "[paths...?]",
+						Action:    cmd.StashPush,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "message",
+								Aliases: []string{"m"},
+								Usage:   "Stash message",
+							},
+						},
+					},
+					{
+						Name:   "list",
+						Usage:  "List stashes, newest first",
+						Action: cmd.ListStashes,
+					},
+					{
+						Name:      "apply",
+						Usage:     "Reapply a stash (defaults to the newest one)",
+						ArgsUsage: "[index?]",
+						Action:    cmd.StashApply,
+					},
+					{
+						Name:      "pop",
+						Usage:     "Reapply a stash (defaults to the newest one) and delete it",
+						ArgsUsage: "[index?]",
+						Action:    cmd.StashPop,
+					},
+					{
+						Name:      "drop",
+						Usage:     "Delete a stash (defaults to the newest one)",
+						ArgsUsage: "[index?]",
+						Action:    cmd.StashDrop,
+					},
+				},
+			},
+			{
+				Name:  "tag",
+				Usage: "Name commits, e.g. for releases and milestones",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "create",
+						Usage:     "Create a tag pointing at a commit (defaults to the current one)",
+						ArgsUsage: "[name] [revision?]",
+						Action:    cmd.CreateTag,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "message",
+								Aliases: []string{"m"},
+								Usage:   "Tag message",
+							},
+						},
+					},
+					{
+						Name:   "list",
+						Usage:  "List tags in the project",
+						Action: cmd.ListTags,
+					},
+					{
+						Name:      "delete",
+						Usage:     "Delete a tag",
+						ArgsUsage: "[name]",
+						Action:    cmd.DeleteTag,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "yes",
+								Aliases: []string{"y"},
+								Usage:   "Skip confirmation",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:    "changelist",
+				Usage:   "Group local changes to push them separately",
+				Aliases: []string{"cl"},
+				Subcommands: []*cli.Command{
+					{
+						Name:      "new",
+						Usage:     "Create a new changelist",
+						ArgsUsage: "[name]",
+						Action:    cmd.NewChangelist,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "message",
+								Aliases: []string{"m"},
+								Usage:   "Changelist description, used as the commit message when pushing it",
+							},
+						},
+					},
+					{
+						Name:      "add",
+						Usage:     "Add files or directories to a changelist, moving them out of any other one",
+						ArgsUsage: "[name] [paths...]",
+						Action:    cmd.AddToChangelist,
+					},
+					{
+						Name:      "remove",
+						Usage:     "Remove files or directories from their changelist",
+						ArgsUsage: "[paths...]",
+						Action:    cmd.RemoveFromChangelist,
+					},
+					{
+						Name:   "list",
+						Usage:  "List changelists",
+						Action: cmd.ListChangelists,
+					},
+					{
+						Name:      "delete",
+						Usage:     "Delete a changelist, leaving its files untouched",
+						ArgsUsage: "[name]",
+						Action:    cmd.DeleteChangelist,
+					},
+				},
+			},
+			{
+				Name:  "shelve",
+				Usage: "Share local changes with your team without committing them",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "create",
+						Usage:     "Upload local changes into a new shelf, keeping them locally",
+						ArgsUsage: "[paths...?]",
+						Action:    cmd.CreateShelf,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "name",
+								Aliases: []string{"n"},
+								Usage:   "Shelf name (defaults to one based on the current time)",
+							},
+							&cli.StringFlag{
+								Name:    "message",
+								Aliases: []string{"m"},
+								Usage:   "Shelf message",
+							},
+						},
+					},
+					{
+						Name:   "list",
+						Usage:  "List shelves in the project",
+						Action: cmd.ListShelves,
+					},
+					{
+						Name:      "apply",
+						Usage:     "Apply a shelf's changes to the working copy",
+						ArgsUsage: "[name]",
+						Action:    cmd.ApplyShelf,
+					},
+					{
+						Name:      "delete",
+						Usage:     "Delete a shelf",
+						ArgsUsage: "[name]",
+						Action:    cmd.DeleteShelf,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "yes",
+								Aliases: []string{"y"},
+								Usage:   "Skip confirmation",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "bisect",
+				Usage: "Find the commit that introduced a problem by binary search",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "start",
+						Usage:  "Start bisecting between a good and a bad commit",
+						Action: cmd.BisectStart,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "good",
+								Usage: "Revision known to be good",
+							},
+							&cli.StringFlag{
+								Name:  "bad",
+								Usage: "Revision known to be bad",
+								Value: "head",
+							},
+							&cli.BoolFlag{
+								Name:    "yes",
+								Aliases: []string{"y"},
+								Usage:   "Skip confirmation",
+							},
+						},
+					},
+					{
+						Name:      "good",
+						Usage:     "Mark the current (or specified) commit as good",
+						ArgsUsage: "[revision?]",
+						Action:    cmd.BisectGood,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "yes",
+								Aliases: []string{"y"},
+								Usage:   "Skip confirmation",
+							},
+						},
+					},
+					{
+						Name:      "bad",
+						Usage:     "Mark the current (or specified) commit as bad",
+						ArgsUsage: "[revision?]",
+						Action:    cmd.BisectBad,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "yes",
+								Aliases: []string{"y"},
+								Usage:   "Skip confirmation",
+							},
+						},
+					},
+					{
+						Name:      "skip",
+						Usage:     "Mark the current (or specified) commit as untestable",
+						ArgsUsage: "[revision?]",
+						Action:    cmd.BisectSkip,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "yes",
+								Aliases: []string{"y"},
+								Usage:   "Skip confirmation",
+							},
+						},
+					},
+					{
+						Name:      "run",
+						Usage:     "Bisect automatically using a command's exit code (0 = good, 125 = skip, 1-127 = bad)",
+						ArgsUsage: "[command...]",
+						Action:    cmd.BisectRun,
+					},
+					{
+						Name:   "reset",
+						Usage:  "End bisecting and sync back to the original commit",
+						Action: cmd.BisectReset,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "yes",
+								Aliases: []string{"y"},
+								Usage:   "Skip confirmation",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:   "purge",
+				Usage:  "Permanently delete stored files that are no longer used by any commit or shelf",
+				Action: cmd.Purge,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Skip confirmation",
+					},
+				},
+			},
+			{
+				Name:  "recover",
+				Usage: "Restore local files that were replaced or deleted by sync, reset, or merge",
+				Subcommands: []*cli.Command{
+					{
+						Name:    "list",
+						Aliases: []string{"l"},
+						Usage:   "List recovery snapshots",
+						Action:  cmd.ListRecoverySnapshots,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "files",
+								Usage: "List files in each snapshot",
+							},
+						},
+					},
+					{
+						Name:      "restore",
+						Aliases:   []string{"r"},
+						Usage:     "Restore files from a recovery snapshot",
+						ArgsUsage: "[id] [paths...?]",
+						Action:    cmd.Recover,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "yes",
+								Aliases: []string{"y"},
+								Usage:   "Skip confirmation",
+							},
+						},
+					},
+				},
+			},
 			{
 				Name:   "status",
 				Usage:  "Print local project status",
@@ -149,6 +557,24 @@ func main() {
 						Usage:     "Create a new branch",
 						ArgsUsage: "[name]",
 						Action:    cmd.NewBranch,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "from",
+								Usage: "Revision to create the branch from, which the working copy is synced to",
+								Value: "head",
+							},
+							&cli.BoolFlag{
+								Name:    "yes",
+								Aliases: []string{"y"},
+								Usage:   "Skip confirmation",
+							},
+						},
+					},
+					{
+						Name:      "compare",
+						Usage:     "List the commits and files unique to each of two branches (or a branch and the current one)",
+						ArgsUsage: "[branch] [other_branch?]",
+						Action:    cmd.CompareBranches,
 					},
 					{
 						Name:      "use",
@@ -178,6 +604,12 @@ func main() {
 							},
 						},
 					},
+					{
+						Name:      "restore",
+						Usage:     "Restore a deleted branch",
+						ArgsUsage: "[name]",
+						Action:    cmd.RestoreBranch,
+					},
 					{
 						Name:      "set-default",
 						Aliases:   []string{"sd"},
@@ -197,11 +629,18 @@ func main() {
 				Name:   "branches",
 				Usage:  "List all branches in the project",
 				Action: cmd.ListBranches,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "verbose",
+						Aliases: []string{"v"},
+						Usage:   "Also print each branch's last author and date, and how many commits it's ahead of or behind the current branch",
+					},
+				},
 			},
 			{
 				Name:      "merge",
 				Usage:     "Merge a branch into the current branch",
-				ArgsUsage: "[branch]",
+				ArgsUsage: "[revision]",
 				Flags: []cli.Flag{
 					&cli.BoolFlag{
 						Name:    "yes",
@@ -227,6 +666,81 @@ func main() {
 						Usage:   "Limit number of commits",
 						Value:   10,
 					},
+					&cli.IntFlag{
+						Name:  "page",
+						Usage: "Page of commits to print, with --limit commits per page",
+						Value: 1,
+					},
+					&cli.StringFlag{
+						Name:    "branch",
+						Aliases: []string{"b"},
+						Usage:   "Only include commits on branch",
+					},
+					&cli.StringFlag{
+						Name:    "author",
+						Aliases: []string{"a"},
+						Usage:   "Only include commits by author (name, email, or user ID)",
+					},
+					&cli.StringFlag{
+						Name:  "since",
+						Usage: "Only include commits made on or after date (YYYY-MM-DD or RFC 3339)",
+					},
+					&cli.StringFlag{
+						Name:  "until",
+						Usage: "Only include commits made on or before date (YYYY-MM-DD or RFC 3339)",
+					},
+					&cli.StringFlag{
+						Name:    "grep",
+						Aliases: []string{"g"},
+						Usage:   "Only include commits whose message contains text",
+					},
+					&cli.StringSliceFlag{
+						Name:    "path",
+						Aliases: []string{"p"},
+						Usage:   "Only include commits that created, modified, or deleted path",
+					},
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Usage:   "Go template used to print each commit (e.g. \"{{.Index}} {{.Author}} {{.Message}}\")",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print commits as JSON",
+					},
+				},
+			},
+			{
+				Name:      "log",
+				Usage:     "List commits that created, modified, or deleted a file",
+				ArgsUsage: "[path]",
+				Action:    cmd.PrintFileHistory,
+				Flags:     fileHistoryFlags,
+			},
+			{
+				Name:  "file",
+				Usage: "File commands",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "history",
+						Usage:     "List commits that created, modified, or deleted a file",
+						ArgsUsage: "[path]",
+						Action:    cmd.PrintFileHistory,
+						Flags:     fileHistoryFlags,
+					},
+				},
+			},
+			{
+				Name:      "show",
+				Usage:     "Print commit details and changed files",
+				ArgsUsage: "[revision]",
+				Action:    cmd.Show,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "patch",
+						Aliases: []string{"p"},
+						Usage:   "Print text diffs against the previous commit",
+					},
 				},
 			},
 			{
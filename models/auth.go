@@ -15,3 +15,8 @@ type AuthenticateResponse struct {
 type AuthWebhookRequest struct {
 	SessionToken string `json:"session_token" validate:"required"`
 }
+
+// Response body for `/session`
+type SessionResponse struct {
+	UserID string `json:"user_id"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// State of an ongoing bisect, saved in the project data directory between invocations.
+type BisectState struct {
+	// Commit index the working copy was on before bisecting started.
+	OriginalCommitIndex int `json:"original_commit_index"`
+	// Index of the latest commit known to be good.
+	Good int `json:"good"`
+	// Index of the earliest commit known to be bad.
+	Bad int `json:"bad"`
+	// Indices of commits between good and bad that are left to test, in ascending order.
+	Candidates []int `json:"candidates"`
+	// Indices of commits that couldn't be tested.
+	Skipped []int `json:"skipped"`
+}
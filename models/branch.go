@@ -10,6 +10,9 @@ type Branch struct {
 	ProjectID string            `json:"project_id,omitempty"`
 	CommitID  string            `json:"commit_id,omitempty"`
 	Locks     map[string]string `json:"locks,omitempty"`
+	// Index of the commit the branch was created from (`BranchCreateDTO.CommitIndex`).
+	// 0 for the project's first branch.
+	ForkCommitIndex int `json:"fork_commit_index,omitempty"`
 }
 
 type BranchWithCommit struct {
@@ -20,6 +23,9 @@ type BranchWithCommit struct {
 	ProjectID string            `json:"project_id,omitempty"`
 	Commit    Commit            `json:"commit,omitempty"`
 	Locks     map[string]string `json:"locks,omitempty"`
+	// Index of the commit the branch was created from (`BranchCreateDTO.CommitIndex`).
+	// 0 for the project's first branch.
+	ForkCommitIndex int `json:"fork_commit_index,omitempty"`
 }
 
 type BranchCreateDTO struct {
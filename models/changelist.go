@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Named group of local files, used to push unrelated local changes separately.
+type Changelist struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Relative fs paths of files or directories in the changelist.
+	Paths []string `json:"paths"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// Model for the project's commit message config file, which is shared by everyone in the project.
+type CommitConfig struct {
+	// Text to prefill the commit message editor with.
+	Template string `yaml:"template"`
+	// Min amount of characters in commit messages.
+	MinLength int `yaml:"min_length"`
+	// Regex that commit messages must match (e.g. a ticket number).
+	RequiredPattern string `yaml:"required_pattern"`
+}
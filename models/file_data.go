@@ -8,5 +8,8 @@ type FileData struct {
 	PatchHashes []string `json:"patch_hashes"`
 	// Version number of this file. Starts at 1.
 	// For example, if the file has been uploaded and changed twice, then this will be 3.
-	Version uint8 `json:"version"`
+	Version uint32 `json:"version"`
+	// File size in bytes.
+	// Commits made before sizes were recorded have a size of 0.
+	Size int64 `json:"size,omitempty"`
 }
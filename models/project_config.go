@@ -5,4 +5,7 @@ type ProjectConfig struct {
 	ProjectSlug        string `yaml:"project" validate:"required"`
 	CurrentBranchName  string `yaml:"branch" validate:"required"`
 	CurrentCommitIndex int    `yaml:"commit" validate:"required,gt=0"`
+	// True if the current commit doesn't belong to the current branch, e.g. after syncing to a
+	// commit on another branch. Pushing is not allowed in this state.
+	Detached bool `yaml:"detached,omitempty"`
 }
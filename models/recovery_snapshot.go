@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Metadata for a snapshot of local files that were replaced or deleted by a destructive operation
+// (e.g. sync, reset, or merge). Stored locally in the project's recovery directory.
+type RecoverySnapshot struct {
+	// Snapshot ID, which is also the name of its directory.
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	// Name of the operation that replaced the files (e.g. "sync").
+	Operation string `json:"operation"`
+	// Index of the commit the project was on when the snapshot was taken.
+	CommitIndex int `json:"commit_index"`
+	// Relative fs paths of the backed up files.
+	Files []string `json:"files"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Uncommitted changes shared with other users of the project.
+type Shelf struct {
+	ID        string    `json:"_id,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	ProjectID string    `json:"project_id,omitempty"`
+	// ID of the user who created the shelf.
+	AuthorID string `json:"author_id,omitempty"`
+	// Branch the changes were made on.
+	BranchName string `json:"branch_name,omitempty"`
+	// Commit the changes were made on.
+	CommitIndex int `json:"commit_index,omitempty"`
+	// Array of relative fs paths to created files (uploaded as snapshots)
+	CreatedFiles []string `json:"created_files,omitempty"`
+	// Array of relative fs paths to modified files (uploaded as snapshots)
+	ModifiedFiles []string `json:"modified_files,omitempty"`
+	// Array of relative fs paths to deleted files
+	DeletedFiles []string `json:"deleted_files,omitempty"`
+	// Map of relative fs paths of created and modified files to their shelved data
+	Files map[string]FileData `json:"files,omitempty"`
+	// Map of relative fs paths of modified and deleted files to their data in the commit the changes
+	// were made on, used as the base when applying them.
+	BaseFiles map[string]FileData `json:"base_files,omitempty"`
+}
+
+type ShelfCreateDTO struct {
+	Name          string              `json:"name"`
+	Message       string              `json:"message,omitempty"`
+	BranchName    string              `json:"branch_name,omitempty"`
+	CommitIndex   int                 `json:"commit_index,omitempty"`
+	CreatedFiles  []string            `json:"created_files"`
+	ModifiedFiles []string            `json:"modified_files"`
+	DeletedFiles  []string            `json:"deleted_files"`
+	Files         map[string]FileData `json:"files"`
+	BaseFiles     map[string]FileData `json:"base_files"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Local changes saved with `dvcs stash push`, to be reapplied later.
+type Stash struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Message   string    `json:"message,omitempty"`
+	// Branch the changes were made on.
+	BranchName string `json:"branch_name"`
+	// Commit the changes were made on.
+	CommitIndex int `json:"commit_index"`
+	// Relative fs paths of created files (contents saved in the stash)
+	CreatedFiles []string `json:"created_files"`
+	// Relative fs paths of modified files (contents saved in the stash)
+	ModifiedFiles []string `json:"modified_files"`
+	// Relative fs paths of deleted files
+	DeletedFiles []string `json:"deleted_files"`
+	// Data of modified and deleted files in the commit the changes were made on, used as the base
+	// when reapplying them.
+	BaseFiles map[string]FileData `json:"base_files"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Named reference to a commit, e.g. for releases and milestones.
+type Tag struct {
+	ID          string    `json:"_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	ProjectID   string    `json:"project_id,omitempty"`
+	CommitIndex int       `json:"commit_index,omitempty"`
+	// ID of the user who created the tag.
+	AuthorID string `json:"author_id,omitempty"`
+}
+
+type TagCreateDTO struct {
+	Name        string `json:"name"`
+	Message     string `json:"message,omitempty"`
+	CommitIndex int    `json:"commit_index"`
+}
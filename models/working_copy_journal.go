@@ -0,0 +1,22 @@
+package models
+
+type WorkingCopyJournalState string
+
+const (
+	// Staged files are being moved into the working copy.
+	JournalStateApplying WorkingCopyJournalState = "applying"
+)
+
+// On-disk journal for a working copy update, written once all files are staged and used to complete
+// updates that were interrupted (e.g. by a crash).
+type WorkingCopyJournal struct {
+	State WorkingCopyJournalState `json:"state"`
+	// Name of the operation performing the update (e.g. "sync").
+	Operation string `json:"operation"`
+	// Relative fs paths of staged files to move into the working copy.
+	Writes []string `json:"writes"`
+	// Relative fs paths of files to delete from the working copy.
+	Deletes []string `json:"deletes"`
+	// Commit index to save in the project config once applied. 0 leaves it unchanged.
+	CommitIndex int `json:"commit_index,omitempty"`
+}